@@ -0,0 +1,56 @@
+package aurum
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// callerDir returns the directory containing the source file of the function
+// skip frames above the caller of callerDir. The current directory is returned
+// when the path isn't available or not absolute, e.g. in binaries built with
+// "-trimpath". "go test" runs tests in the package directory, making this a
+// reasonable fallback for test files.
+func callerDir(skip int) string {
+	if _, file, _, ok := runtime.Caller(skip + 1); ok && filepath.IsAbs(file) {
+		return filepath.Dir(file)
+	}
+
+	return "."
+}
+
+// testCallerDir is like [callerDir], but prefers the directory of the first
+// test source file ("_test.go" suffix) found further up the stack. Helpers
+// shared between packages are thereby skipped. The directory of the initial
+// frame is used when no test file is found.
+func testCallerDir(skip int) string {
+	pc := make([]uintptr, 64)
+	pc = pc[:runtime.Callers(skip+2, pc)]
+
+	var first string
+
+	frames := runtime.CallersFrames(pc)
+
+	for {
+		frame, more := frames.Next()
+
+		if first == "" {
+			first = frame.File
+		}
+
+		if strings.HasSuffix(frame.File, "_test.go") {
+			first = frame.File
+			break
+		}
+
+		if !more || strings.HasPrefix(frame.Function, "testing.") {
+			break
+		}
+	}
+
+	if filepath.IsAbs(first) {
+		return filepath.Dir(first)
+	}
+
+	return "."
+}
@@ -0,0 +1,67 @@
+package aurum
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func assertSameDir(t *testing.T, got, want string) {
+	t.Helper()
+
+	if !filepath.IsAbs(got) {
+		t.Errorf("Directory %q is not absolute", got)
+	}
+
+	if gotInfo, err := os.Stat(got); err != nil {
+		t.Errorf("Stat() failed: %v", err)
+	} else if wantInfo, err := os.Stat(want); err != nil {
+		t.Errorf("Stat() failed: %v", err)
+	} else if !os.SameFile(wantInfo, gotInfo) {
+		t.Errorf("Directory %q doesn't refer to %q", got, want)
+	}
+}
+
+func TestCallerDir(t *testing.T) {
+	// Tests run in the package directory.
+	assertSameDir(t, callerDir(0), ".")
+	assertSameDir(t, testCallerDir(0), ".")
+}
+
+func TestGoldenCallerRelative(t *testing.T) {
+	want := filepath.Join("testdata", "embed")
+
+	t.Run("enabled", func(t *testing.T) {
+		o := Golden{
+			Dir:                 want,
+			DirRelativeToCaller: true,
+		}
+
+		assertSameDir(t, o.callerRelative(0).Dir, want)
+	})
+
+	for _, tc := range []struct {
+		name string
+		opts Golden
+	}{
+		{
+			name: "disabled",
+			opts: Golden{Dir: want},
+		},
+		{
+			name: "absolute",
+			opts: Golden{Dir: t.TempDir(), DirRelativeToCaller: true},
+		},
+		{
+			name: "custom fs",
+			opts: Golden{Dir: want, FS: fstest.MapFS{}, DirRelativeToCaller: true},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.opts.callerRelative(0).Dir; got != tc.opts.Dir {
+				t.Errorf("callerRelative() changed directory to %q, want %q", got, tc.opts.Dir)
+			}
+		})
+	}
+}
@@ -0,0 +1,313 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"image/png"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hansmi/aurum"
+	"github.com/hansmi/aurum/internal/codecutil"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	// Well-known types are available without a descriptor set.
+	_ "google.golang.org/protobuf/types/known/anypb"
+	_ "google.golang.org/protobuf/types/known/durationpb"
+	_ "google.golang.org/protobuf/types/known/emptypb"
+	_ "google.golang.org/protobuf/types/known/fieldmaskpb"
+	_ "google.golang.org/protobuf/types/known/structpb"
+	_ "google.golang.org/protobuf/types/known/timestamppb"
+	_ "google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// errUnsupported is returned for golden files which can't be processed
+// generically. Such files are skipped.
+var errUnsupported = errors.New("unsupported")
+
+var namedCodecs = map[string]func() aurum.Codec{
+	"json":      func() aurum.Codec { return &aurum.JSONCodec{} },
+	"textproto": func() aurum.Codec { return &aurum.TextProtoCodec{} },
+	"text":      func() aurum.Codec { return &aurum.TextCodec{} },
+	"xml":       func() aurum.Codec { return &aurum.XMLCodec{} },
+	"csv":       func() aurum.Codec { return &aurum.CSVCodec{} },
+	"tsv":       func() aurum.Codec { return &aurum.CSVCodec{Comma: '\t'} },
+	"hexdump":   func() aurum.Codec { return &aurum.HexdumpCodec{} },
+	"png":       func() aurum.Codec { return &aurum.ImageCodec{} },
+}
+
+func codecNames() string {
+	var names []string
+
+	for name := range namedCodecs {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return strings.Join(names, ", ")
+}
+
+func newNamedCodec(name string) (aurum.Codec, error) {
+	if fn, ok := namedCodecs[name]; ok {
+		return fn(), nil
+	}
+
+	return nil, fmt.Errorf("%w: unknown codec %q, supported are %s", os.ErrInvalid, name, codecNames())
+}
+
+// codecOptions contains the flags for selecting codecs and value types.
+type codecOptions struct {
+	codec         string
+	message       string
+	descriptorSet string
+
+	files *protoregistry.Files
+}
+
+func (o *codecOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.codec, "codec", "",
+		fmt.Sprintf("Codec for reading golden files instead of selecting by extension (%s).", codecNames()))
+	fs.StringVar(&o.message, "message", "",
+		`Full name of the protocol buffer message stored in the golden files. Defaults to the "# proto-message:" comment of textproto files.`)
+	fs.StringVar(&o.descriptorSet, "descriptor_set", "",
+		"File containing a serialized FileDescriptorSet for resolving message types.")
+}
+
+// load reads the descriptor set, if any.
+func (o *codecOptions) load() error {
+	if o.codec != "" {
+		if _, err := newNamedCodec(o.codec); err != nil {
+			return err
+		}
+	}
+
+	if o.descriptorSet == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(o.descriptorSet)
+	if err != nil {
+		return err
+	}
+
+	var set descriptorpb.FileDescriptorSet
+
+	if err := proto.Unmarshal(data, &set); err != nil {
+		return fmt.Errorf("parsing descriptor set %s: %w", o.descriptorSet, err)
+	}
+
+	if o.files, err = protodesc.NewFiles(&set); err != nil {
+		return fmt.Errorf("loading descriptor set %s: %w", o.descriptorSet, err)
+	}
+
+	return nil
+}
+
+// leadingComments returns the values of "# key: value" lines in the leading
+// comment block of a textproto file.
+func leadingComments(data []byte, key string) []string {
+	var result []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" {
+			continue
+		}
+
+		if !strings.HasPrefix(line, "#") {
+			break
+		}
+
+		if k, value, ok := strings.Cut(line[1:], ":"); ok && strings.TrimSpace(k) == key {
+			result = append(result, strings.TrimSpace(value))
+		}
+	}
+
+	return result
+}
+
+// codecFor returns the codec for reading a golden file. The data is used to
+// detect settings needed for a canonical round trip.
+func (o *codecOptions) codecFor(path string, data []byte) aurum.Codec {
+	var c aurum.Codec
+
+	if o.codec != "" {
+		c, _ = newNamedCodec(o.codec)
+	} else {
+		c = (&aurum.ExtensionCodec{}).CodecFor(path)
+	}
+
+	if _, ok := c.(*aurum.TextProtoCodec); ok && len(leadingComments(data, "proto-message")) > 0 {
+		c = &aurum.TextProtoCodec{SchemaComments: true}
+	}
+
+	return c
+}
+
+// checkHeader returns an error if the data was written with
+// [aurum.Golden.Header] enabled.
+func checkHeader(c aurum.Codec, data []byte) error {
+	if _, ok := c.(*aurum.JSONCodec); ok {
+		var envelope struct {
+			Header json.RawMessage `json:"aurum"`
+		}
+
+		if json.Unmarshal(data, &envelope) != nil || envelope.Header == nil {
+			return nil
+		}
+	} else if !(bytes.HasPrefix(data, []byte("# aurum: ")) || bytes.HasPrefix(data, []byte("<!-- aurum: "))) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: golden file header", errUnsupported)
+}
+
+func (o *codecOptions) newMessage(name string) (proto.Message, error) {
+	fullName := protoreflect.FullName(name)
+
+	if !fullName.IsValid() {
+		return nil, fmt.Errorf("%w: invalid message name %q", os.ErrInvalid, name)
+	}
+
+	if o.files != nil {
+		desc, err := o.files.FindDescriptorByName(fullName)
+		if err == nil {
+			md, ok := desc.(protoreflect.MessageDescriptor)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s is not a message", os.ErrInvalid, name)
+			}
+
+			return dynamicpb.NewMessage(md), nil
+		}
+
+		if !errors.Is(err, protoregistry.NotFound) {
+			return nil, err
+		}
+	}
+
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(fullName)
+	if err != nil {
+		return nil, fmt.Errorf("message %s: %w", name, err)
+	}
+
+	return mt.New().Interface(), nil
+}
+
+// messageName returns the name of the protocol buffer message stored in the
+// data or an empty string if it's not known.
+func (o *codecOptions) messageName(c aurum.Codec, data []byte) string {
+	if o.message != "" {
+		return o.message
+	}
+
+	if _, ok := c.(*aurum.TextProtoCodec); ok {
+		if names := leadingComments(data, "proto-message"); len(names) > 0 {
+			return names[0]
+		}
+	}
+
+	return ""
+}
+
+// genericType returns the value type for decoding data without knowing the
+// type used by the test. The type is a pointer as expected by
+// [codecutil.Unmarshal].
+func genericType(c aurum.Codec, data []byte) (reflect.Type, error) {
+	switch name := aurum.CodecName(c); name {
+	case "json":
+		// Numbers and key order are retained.
+		return reflect.TypeOf((*json.RawMessage)(nil)), nil
+	case "text":
+		return reflect.TypeOf((*string)(nil)), nil
+	case "csv":
+		return reflect.TypeOf((*[][]string)(nil)), nil
+	case "hexdump":
+		return reflect.TypeOf((*[]byte)(nil)), nil
+	case "png":
+		// Retain the color model of the original image.
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+
+		return reflect.TypeOf(img), nil
+	case "textproto":
+		return nil, fmt.Errorf(`%w: message type unknown, use -message or a "# proto-message:" comment`, errUnsupported)
+	default:
+		return nil, fmt.Errorf("%w: no generic value type for codec %s", errUnsupported, name)
+	}
+}
+
+// decode unmarshals the data into a protocol buffer message, if the message
+// type is known, or into a generic value.
+func (o *codecOptions) decode(c aurum.Codec, data []byte) (any, error) {
+	if name := o.messageName(c, data); name != "" {
+		m, err := o.newMessage(name)
+		if err != nil {
+			return nil, err
+		}
+
+		// Dynamic messages can't be constructed from their type alone,
+		// hence codecutil.Unmarshal isn't used.
+		if err := c.Unmarshal(data, &m); err != nil {
+			return nil, err
+		}
+
+		return m, nil
+	}
+
+	t, err := genericType(c, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return codecutil.Unmarshal(c, data, t)
+}
+
+// canonicalize reads a golden file and returns its content together with the
+// canonical form produced by the codec.
+func (o *codecOptions) canonicalize(path string) ([]byte, []byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if isArchive(path, data) {
+		return nil, nil, fmt.Errorf("%w: txtar archive", errUnsupported)
+	}
+
+	c := o.codecFor(path, data)
+
+	if err := checkHeader(c, data); err != nil {
+		return nil, nil, err
+	}
+
+	value, err := o.decode(c, data)
+	if errors.Is(err, errUnsupported) {
+		return nil, nil, err
+	} else if err != nil {
+		return nil, nil, fmt.Errorf("decoding: %w", err)
+	}
+
+	canonical, err := c.Marshal(value)
+	if err != nil {
+		return nil, nil, fmt.Errorf("encoding: %w", err)
+	}
+
+	return data, canonical, nil
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/hansmi/aurum"
+)
+
+func runList(c *commandEnv, args []string) error {
+	var opts codecOptions
+
+	fs := c.newFlagSet()
+	opts.register(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	if err := opts.load(); err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+
+	if err := walkGoldenFiles(fs.Args(), func(path string) error {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		name := "txtar"
+
+		if !isArchive(path, data) {
+			name = aurum.CodecName(opts.codecFor(path, data))
+		}
+
+		_, err = fmt.Fprintf(w, "%s\t%s\n", name, path)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	return w.Flush()
+}
+
+func runVerify(c *commandEnv, args []string) error {
+	var opts codecOptions
+
+	fs := c.newFlagSet()
+	opts.register(fs)
+	verbose := fs.Bool("v", false, "Also report files in canonical form.")
+
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	if err := opts.load(); err != nil {
+		return err
+	}
+
+	var problems bool
+
+	if err := walkGoldenFiles(fs.Args(), func(path string) error {
+		data, canonical, err := opts.canonicalize(path)
+
+		switch {
+		case errors.Is(err, errUnsupported):
+			fmt.Fprintf(c.stdout, "%s: skipped: %v\n", path, err)
+		case err != nil:
+			fmt.Fprintf(c.stdout, "%s: %v\n", path, err)
+			problems = true
+		case !bytes.Equal(data, canonical):
+			fmt.Fprintf(c.stdout, "%s: not in canonical form\n", path)
+			problems = true
+		case *verbose:
+			fmt.Fprintf(c.stdout, "%s: ok\n", path)
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if problems {
+		return errProblemsFound
+	}
+
+	return nil
+}
+
+func runFmt(c *commandEnv, args []string) error {
+	var opts codecOptions
+
+	fs := c.newFlagSet()
+	opts.register(fs)
+	dryRun := fs.Bool("n", false, "List files not in canonical form without modifying them.")
+
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	if err := opts.load(); err != nil {
+		return err
+	}
+
+	var problems bool
+
+	if err := walkGoldenFiles(fs.Args(), func(path string) error {
+		data, canonical, err := opts.canonicalize(path)
+
+		if errors.Is(err, errUnsupported) {
+			return nil
+		}
+
+		if err == nil && !bytes.Equal(data, canonical) {
+			fmt.Fprintln(c.stdout, path)
+
+			if !*dryRun {
+				err = os.WriteFile(path, canonical, 0o644)
+			}
+		}
+
+		if err != nil {
+			fmt.Fprintf(c.stderr, "%s: %v\n", path, err)
+			problems = true
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if problems {
+		return errProblemsFound
+	}
+
+	return nil
+}
+
+func runConvert(c *commandEnv, args []string) error {
+	var opts codecOptions
+
+	fs := c.newFlagSet()
+	opts.register(fs)
+	to := fs.String("to", "", "Codec for writing the destination instead of selecting by extension.")
+
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return errUsage
+	}
+
+	if err := opts.load(); err != nil {
+		return err
+	}
+
+	src, dest := fs.Arg(0), fs.Arg(1)
+
+	outCodec := (&aurum.ExtensionCodec{}).CodecFor(dest)
+
+	if *to != "" {
+		var err error
+
+		if outCodec, err = newNamedCodec(*to); err != nil {
+			return err
+		}
+	}
+
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+
+	if isArchive(src, data) {
+		return fmt.Errorf("%s: %w: txtar archive", src, errUnsupported)
+	}
+
+	inCodec := opts.codecFor(src, data)
+
+	if err := checkHeader(inCodec, data); err != nil {
+		return fmt.Errorf("%s: %w", src, err)
+	}
+
+	value, err := opts.decode(inCodec, data)
+	if err != nil {
+		return fmt.Errorf("decoding %s: %w", src, err)
+	}
+
+	output, err := outCodec.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("encoding %s: %w", dest, err)
+	}
+
+	return os.WriteFile(dest, output, 0o644)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/hansmi/aurum/internal/txtar"
+)
+
+// artifactSuffixes are appended to golden file names by comparers writing
+// diffs next to the golden file.
+var artifactSuffixes = []string{".diff.txt", ".diff.png"}
+
+// legacySuffix is appended to all names by filesystems for the layout of other
+// golden file libraries (see [aurum.GoldieLayout]), including diff artifacts.
+const legacySuffix = ".golden"
+
+func isIgnored(name string) bool {
+	if strings.HasPrefix(name, ".") && name != "." && name != ".." {
+		return true
+	}
+
+	for _, suffix := range artifactSuffixes {
+		if strings.HasSuffix(name, suffix) || strings.HasSuffix(name, suffix+legacySuffix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isArchive reports whether a file is a txtar archive containing multiple
+// golden files, i.e. a [aurum.TxtarFS] archive or a snapshot stored using
+// a codec other than JSON (see [aurum.Snapshot]). Snapshots are detected by
+// content as they have no file extension.
+func isArchive(path string, data []byte) bool {
+	if filepath.Ext(path) == ".txtar" {
+		return true
+	}
+
+	a := txtar.Parse(data)
+
+	return len(a.Comment) == 0 && len(a.Files) > 0
+}
+
+// walkGoldenFiles calls fn for all golden files below the given paths. Hidden
+// files and directories as well as diff artifacts are skipped unless given
+// explicitly.
+func walkGoldenFiles(paths []string, fn func(path string) error) error {
+	for _, root := range paths {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if path != root && isIgnored(d.Name()) {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+
+				return nil
+			}
+
+			if !d.Type().IsRegular() {
+				return nil
+			}
+
+			return fn(path)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,137 @@
+// Command aurum manages golden files outside of "go test".
+//
+// Usage:
+//
+//	aurum list [flags] PATH...
+//	aurum verify [flags] PATH...
+//	aurum fmt [-n] [flags] PATH...
+//	aurum convert [flags] SRC DEST
+//
+// The "list" command prints all golden files below the given paths together
+// with the codec selected by their extension. Files without a registered
+// extension use the JSON codec, as with [aurum.ExtensionCodec]. Txtar
+// archives, i.e. "*.txtar" files of [aurum.TxtarFS] and snapshots not using
+// the JSON codec (see [aurum.Snapshot]), are listed as "txtar" and skipped by
+// the other commands.
+//
+// The "verify" command decodes every golden file and encodes the value again.
+// Files which can't be decoded or whose content differs from the canonical
+// form produced by the codec are reported.
+//
+// The "fmt" command rewrites golden files in their canonical form. With "-n"
+// the files are only listed.
+//
+// The "convert" command decodes a golden file and writes it using another
+// codec, e.g. from JSON to textproto:
+//
+//	aurum convert -descriptor_set desc.binpb -message example.Config \
+//	  testdata/config.json testdata/config.textproto
+//
+// Protocol buffer messages are resolved from the descriptor set given via
+// "-descriptor_set" (a serialized FileDescriptorSet) or from the well-known
+// types. The message type of textproto files is taken from "-message" or from
+// a "# proto-message:" comment. Files of other codecs are decoded into
+// generic values, e.g. strings for text files.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+type command struct {
+	usage string
+	run   func(c *commandEnv, args []string) error
+}
+
+var commands = map[string]command{
+	"list":    {"[flags] PATH...", runList},
+	"verify":  {"[flags] PATH...", runVerify},
+	"fmt":     {"[-n] [flags] PATH...", runFmt},
+	"convert": {"[flags] SRC DEST", runConvert},
+}
+
+// errProblemsFound is returned by commands having reported problems with
+// individual files.
+var errProblemsFound = errors.New("problems found")
+
+// errUsage is returned for invalid command line arguments. The usage has
+// already been printed.
+var errUsage = errors.New("invalid usage")
+
+type commandEnv struct {
+	name   string
+	usage  string
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func (c *commandEnv) newFlagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet(c.name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: aurum %s %s\n", c.name, c.usage)
+		fs.PrintDefaults()
+	}
+
+	return fs
+}
+
+func usage(w io.Writer) {
+	var names []string
+
+	for name := range commands {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	fmt.Fprintf(w, "Usage:\n")
+
+	for _, name := range names {
+		fmt.Fprintf(w, "  aurum %s %s\n", name, commands[name].usage)
+	}
+}
+
+// run executes the command line and returns the exit code.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		usage(stderr)
+		return 2
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "aurum: unknown command %q\n", args[0])
+		usage(stderr)
+		return 2
+	}
+
+	env := &commandEnv{
+		name:   args[0],
+		usage:  cmd.usage,
+		stdout: stdout,
+		stderr: stderr,
+	}
+
+	switch err := cmd.run(env, args[1:]); {
+	case err == nil:
+		return 0
+	case errors.Is(err, errUsage):
+		return 2
+	case errors.Is(err, errProblemsFound):
+		return 1
+	default:
+		fmt.Fprintf(stderr, "aurum %s: %v\n", env.name, err)
+		return 1
+	}
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
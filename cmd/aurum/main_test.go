@@ -0,0 +1,324 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hansmi/aurum/internal/testutil"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func runForTest(t *testing.T, dir string, args ...string) (int, string, string) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+
+	code := run(args, &stdout, &stderr)
+
+	if dir == "" {
+		return code, stdout.String(), stderr.String()
+	}
+
+	// Make paths in the output independent of the temporary directory.
+	replacer := strings.NewReplacer(dir+string(filepath.Separator), "", dir, ".")
+
+	return code, replacer.Replace(stdout.String()), replacer.Replace(stderr.String())
+}
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("MkdirAll() failed: %v", err)
+		}
+
+		testutil.MustWriteFile(t, path, content)
+	}
+
+	return dir
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile(%q) failed: %v", path, err)
+	}
+
+	return string(data)
+}
+
+func TestRunUsage(t *testing.T) {
+	for _, args := range [][]string{
+		nil,
+		{"-help"},
+		{"unknown"},
+		{"list"},
+		{"fmt", "-unknown_flag"},
+		{"convert", "only-source"},
+	} {
+		t.Run(strings.Join(args, " "), func(t *testing.T) {
+			code, _, stderr := runForTest(t, "", args...)
+
+			if code != 2 {
+				t.Errorf("run() returned %d, want 2", code)
+			}
+
+			if !strings.Contains(stderr, "Usage") {
+				t.Errorf("Usage missing from output:\n%s", stderr)
+			}
+		})
+	}
+}
+
+func TestList(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"plain":                     "[]\n",
+		"data.textproto":            "",
+		"sub/table.csv":             "",
+		"sub/image.png":             "",
+		"sub/text.txt.diff.txt":     "",
+		"sub/text.diff.txt.golden":  "",
+		"sub/image.diff.png.golden": "",
+		"sub/TestSnapshot":          "-- first --\n1\n-- second --\n2\n",
+		"archive.txtar":             "",
+		".hidden/file.json":         "",
+		".file.json":                "",
+	})
+
+	code, stdout, stderr := runForTest(t, dir, "list", dir)
+
+	if code != 0 {
+		t.Errorf("run() returned %d, stderr:\n%s", code, stderr)
+	}
+
+	want := strings.Join([]string{
+		"txtar      archive.txtar",
+		"textproto  data.textproto",
+		"json       plain",
+		"txtar      sub/TestSnapshot",
+		"png        sub/image.png",
+		"csv        sub/table.csv",
+		"",
+	}, "\n")
+
+	if diff := cmp.Diff(want, filepath.ToSlash(stdout)); diff != "" {
+		t.Errorf("Output diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"canonical.json":    "{\n  \"number\": 1.50\n}\n",
+		"unformatted.json":  `{"a":1}`,
+		"invalid.json":      "{",
+		"header.json":       "{\n  \"aurum\": {},\n  \"value\": null\n}\n",
+		"text.txt":          "anything",
+		"table.csv":         "a,b\nc,d\n",
+		"unknown.textproto": "a: 1\n",
+		"struct.textproto":  "# proto-file: google/protobuf/struct.proto\n# proto-message: google.protobuf.Struct\n\nfields {\n  key: \"a\"\n  value {\n    number_value: 1\n  }\n}\n",
+		"reorder.textproto": "# proto-message: google.protobuf.Struct\nfields { key: \"a\" value { bool_value: true } }\n",
+		"document.xml":      "<a></a>",
+		"TestSnapshot":      "-- first --\nvalue\n",
+		"golden.txtar":      "comment\n-- value --\n[]\n",
+	})
+
+	code, stdout, stderr := runForTest(t, dir, "verify", "-v", dir)
+
+	if code != 1 {
+		t.Errorf("run() returned %d, want 1, stderr:\n%s", code, stderr)
+	}
+
+	want := strings.Join([]string{
+		"TestSnapshot: skipped: unsupported: txtar archive",
+		"canonical.json: ok",
+		"document.xml: skipped: unsupported: no generic value type for codec xml",
+		"golden.txtar: skipped: unsupported: txtar archive",
+		"header.json: skipped: unsupported: golden file header",
+		"invalid.json: decoding: unexpected end of JSON input",
+		"reorder.textproto: not in canonical form",
+		"struct.textproto: ok",
+		"table.csv: ok",
+		"text.txt: ok",
+		"unformatted.json: not in canonical form",
+		`unknown.textproto: skipped: unsupported: message type unknown, use -message or a "# proto-message:" comment`,
+		"",
+	}, "\n")
+
+	if diff := cmp.Diff(want, stdout); diff != "" {
+		t.Errorf("Output diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestVerifyCanonical(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"value.json": "[\n  1\n]\n",
+	})
+
+	if code, stdout, stderr := runForTest(t, dir, "verify", dir); code != 0 || stdout != "" {
+		t.Errorf("run() returned %d, stdout:\n%s\nstderr:\n%s", code, stdout, stderr)
+	}
+}
+
+func TestFmt(t *testing.T) {
+	files := map[string]string{
+		"canonical.json":   "[\n  1\n]\n",
+		"unformatted.json": `{"b":1,"a":[]}`,
+		"unformatted.csv":  "\"a\",b\n",
+		"invalid.json":     "{",
+	}
+
+	for _, tc := range []struct {
+		name string
+		args []string
+		want map[string]string
+	}{
+		{
+			name: "dry run",
+			args: []string{"-n"},
+			want: files,
+		},
+		{
+			name: "rewrite",
+			want: map[string]string{
+				"canonical.json":   "[\n  1\n]\n",
+				"unformatted.json": "{\n  \"b\": 1,\n  \"a\": []\n}\n",
+				"unformatted.csv":  "a,b\n",
+				"invalid.json":     "{",
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, files)
+
+			code, stdout, stderr := runForTest(t, dir, append(append([]string{"fmt"}, tc.args...), dir)...)
+
+			if code != 1 {
+				t.Errorf("run() returned %d, want 1", code)
+			}
+
+			if diff := cmp.Diff("unformatted.csv\nunformatted.json\n", stdout); diff != "" {
+				t.Errorf("Output diff (-want +got):\n%s", diff)
+			}
+
+			if !strings.Contains(stderr, "invalid.json: decoding:") {
+				t.Errorf("Error for invalid file missing:\n%s", stderr)
+			}
+
+			got := map[string]string{}
+
+			for name := range files {
+				got[name] = readFile(t, filepath.Join(dir, name))
+			}
+
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Files diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"struct.json":      "{\"key\": [true, \"text\"]}\n",
+		"struct.textproto": "# proto-message: google.protobuf.Struct\nfields { key: \"a\" value { null_value: NULL_VALUE } }\n",
+		"text.txt":         "hello\n",
+		"TestSnapshot":     "-- value --\nhello\n",
+	})
+
+	descriptorSet := filepath.Join(dir, "descriptors.binpb")
+
+	if data, err := proto.Marshal(&descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{
+			protodesc.ToFileDescriptorProto(structpb.File_google_protobuf_struct_proto),
+		},
+	}); err != nil {
+		t.Fatalf("Marshal() failed: %v", err)
+	} else {
+		testutil.MustWriteFile(t, descriptorSet, string(data))
+	}
+
+	for _, tc := range []struct {
+		name     string
+		args     []string
+		dest     string
+		want     string
+		wantCode int
+	}{
+		{
+			name: "json to textproto",
+			args: []string{"-message", "google.protobuf.Struct", "struct.json"},
+			dest: "out.textproto",
+			want: "fields {\n  key: \"key\"\n  value {\n    list_value {\n      values {\n        bool_value: true\n      }\n      values {\n        string_value: \"text\"\n      }\n    }\n  }\n}\n",
+		},
+		{
+			name: "descriptor set",
+			args: []string{"-descriptor_set", descriptorSet, "-message", "google.protobuf.Struct", "struct.json"},
+			dest: "out.textproto",
+			want: "fields {\n  key: \"key\"\n  value {\n    list_value {\n      values {\n        bool_value: true\n      }\n      values {\n        string_value: \"text\"\n      }\n    }\n  }\n}\n",
+		},
+		{
+			name: "textproto to json",
+			args: []string{"struct.textproto"},
+			dest: "out.json",
+			want: "{\n  \"a\": null\n}\n",
+		},
+		{
+			name: "explicit codecs",
+			args: []string{"-codec", "text", "-to", "hexdump", "text.txt"},
+			dest: "out",
+			want: "00000000  68 65 6c 6c 6f 0a                                 |hello.|\n00000006\n",
+		},
+		{
+			name:     "unknown message",
+			args:     []string{"-message", "unknown.Message", "struct.json"},
+			dest:     "out.textproto",
+			wantCode: 1,
+		},
+		{
+			name:     "archive",
+			args:     []string{"-codec", "text", "TestSnapshot"},
+			dest:     "out.txt",
+			wantCode: 1,
+		},
+		{
+			name:     "unsupported output",
+			args:     []string{"struct.json"},
+			dest:     "out.textproto",
+			wantCode: 1,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			args := append([]string{"convert"}, tc.args...)
+			args[len(args)-1] = filepath.Join(dir, args[len(args)-1])
+			dest := filepath.Join(t.TempDir(), tc.dest)
+
+			code, _, stderr := runForTest(t, dir, append(args, dest)...)
+
+			if code != tc.wantCode {
+				t.Errorf("run() returned %d, want %d, stderr:\n%s", code, tc.wantCode, stderr)
+			}
+
+			if tc.wantCode == 0 {
+				if diff := cmp.Diff(tc.want, readFile(t, dest)); diff != "" {
+					t.Errorf("Output diff (-want +got):\n%s", diff)
+				}
+			} else {
+				testutil.MustNotExist(t, dest)
+			}
+		})
+	}
+}
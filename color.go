@@ -0,0 +1,95 @@
+package aurum
+
+import (
+	"fmt"
+	"os"
+)
+
+// ColorMode controls the use of ANSI escape sequences for coloring diffs.
+type ColorMode int
+
+const (
+	// Color output if enabled via flag (see [DefaultColorFlagName]) or, if
+	// unset, when the environment indicates support: the NO_COLOR environment
+	// variable must not be set and TERM must be set to a value other than
+	// "dumb".
+	ColorAuto ColorMode = iota
+
+	// Always color output.
+	ColorAlways
+
+	// Never color output.
+	ColorNever
+)
+
+var colorModeNames = [...]string{
+	ColorAuto:   "auto",
+	ColorAlways: "always",
+	ColorNever:  "never",
+}
+
+func (m ColorMode) String() string {
+	if m >= 0 && int(m) < len(colorModeNames) {
+		return colorModeNames[m]
+	}
+
+	return fmt.Sprintf("ColorMode(%d)", int(m))
+}
+
+// colorModeFlag parses color modes from the command line.
+type colorModeFlag struct {
+	m *ColorMode
+}
+
+func (f colorModeFlag) String() string {
+	if f.m == nil {
+		return ColorAuto.String()
+	}
+
+	return f.m.String()
+}
+
+func (f colorModeFlag) Set(value string) error {
+	for idx, name := range colorModeNames {
+		if name == value {
+			*f.m = ColorMode(idx)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: unknown color mode %q", os.ErrInvalid, value)
+}
+
+// colorFromEnv reports whether the environment indicates support for colors.
+func colorFromEnv(lookupEnv func(string) (string, bool)) bool {
+	if _, ok := lookupEnv("NO_COLOR"); ok {
+		return false
+	}
+
+	term, _ := lookupEnv("TERM")
+
+	return term != "" && term != "dumb"
+}
+
+// resolve determines whether colors should be used. Automatic detection
+// consults the global mode before the environment.
+func (m ColorMode) resolve(global ColorMode, lookupEnv func(string) (string, bool)) bool {
+	if m == ColorAuto {
+		m = global
+	}
+
+	switch m {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+
+	return colorFromEnv(lookupEnv)
+}
+
+const (
+	ansiRed   = "\x1b[31m"
+	ansiGreen = "\x1b[32m"
+	ansiReset = "\x1b[0m"
+)
@@ -3,6 +3,7 @@ package aurum
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/google/go-cmp/cmp"
 	"go.uber.org/multierr"
@@ -19,17 +20,162 @@ type Comparer interface {
 	Equal(want, got any) error
 }
 
+// artifactWriter stores supplementary data next to a golden file. The suffix
+// is appended to the golden filename. The name of the written file is
+// returned.
+type artifactWriter func(suffix string, data []byte) (string, error)
+
+// artifactComparer is implemented by comparers able to store files describing
+// a difference, e.g. a visual diff of two images.
+type artifactComparer interface {
+	Comparer
+
+	equalWithArtifacts(want, got any, write artifactWriter) error
+
+	// artifactSuffixes returns the suffixes of all files possibly written by
+	// the comparer. They're removed once values are equal.
+	artifactSuffixes() []string
+}
+
 // Cmp compares values using [cmp.Diff].
 type Cmp struct {
 	// Options for comparing values, e.g. [cmpopts.EqualEmpty]. If one of the
 	// compared values is a [proto.Message] then the [protocmp.Transform]
 	// option is automatically added.
 	Options cmp.Options
+
+	// Maximum number of diff lines included in errors. Longer diffs are
+	// truncated with a marker stating the number of omitted lines. When used
+	// by [Golden] with golden files in a directory the full diff is written
+	// next to the golden file. Zero or negative values disable truncation.
+	MaxDiffLines int
+
+	// Suffix appended to the golden filename for the full diff of a truncated
+	// difference. The file is removed once the values are equal.
+	//
+	// Defaults to ".diff.txt".
+	DiffSuffix string
+
+	// Color removed and added lines using ANSI escape sequences when
+	// differences are logged by [Golden.Assert] or [Snapshot]. Errors
+	// returned by [Cmp.Equal] are never colored.
+	//
+	// Defaults to [ColorAuto].
+	Color ColorMode
+
+	// Only report the paths of differing values instead of a full diff.
+	Compact bool
 }
 
 var _ Comparer = (*Cmp)(nil)
+var _ artifactComparer = (*Cmp)(nil)
+
+// pathCollector is a [cmp.Reporter] collecting the paths of differing
+// values.
+type pathCollector struct {
+	path  cmp.Path
+	paths []string
+}
+
+func (r *pathCollector) PushStep(ps cmp.PathStep) {
+	r.path = append(r.path, ps)
+}
+
+func (r *pathCollector) Report(rs cmp.Result) {
+	if !rs.Equal() {
+		p := r.path.String()
+
+		if p == "" {
+			p = "<root>"
+		}
+
+		r.paths = append(r.paths, p)
+	}
+}
+
+func (r *pathCollector) PopStep() {
+	r.path = r.path[:len(r.path)-1]
+}
+
+// truncateLines limits text to a maximum number of lines. The number of
+// removed lines is returned.
+func truncateLines(text string, maxLines int) (string, int) {
+	lines := strings.SplitAfter(text, "\n")
+
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	if maxLines <= 0 || len(lines) <= maxLines {
+		return text, 0
+	}
+
+	return strings.Join(lines[:maxLines], ""), len(lines) - maxLines
+}
+
+// diffError is a difference reported by [Cmp]. The message is never colored
+// as it's also used for reports. Colors are only added when the error is
+// logged to a test (see [Golden.errorText]).
+type diffError struct {
+	err   error
+	color ColorMode
+}
+
+func (e *diffError) Error() string {
+	return e.err.Error()
+}
+
+func (e *diffError) Unwrap() error {
+	return e.err
+}
 
-func (c Cmp) Equal(want, got any) (err error) {
+// colorizeDiff colors lines removed and added by a diff.
+func colorizeDiff(diff string) string {
+	var sb strings.Builder
+
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		content := strings.TrimSuffix(line, "\n")
+
+		var color string
+
+		switch {
+		case strings.HasPrefix(content, "-"):
+			color = ansiRed
+		case strings.HasPrefix(content, "+"):
+			color = ansiGreen
+		}
+
+		if color == "" {
+			sb.WriteString(line)
+			continue
+		}
+
+		sb.WriteString(color)
+		sb.WriteString(content)
+		sb.WriteString(ansiReset)
+		sb.WriteString(line[len(content):])
+	}
+
+	return sb.String()
+}
+
+func (c Cmp) diffSuffix() string {
+	if c.DiffSuffix == "" {
+		return ".diff.txt"
+	}
+
+	return c.DiffSuffix
+}
+
+func (c Cmp) artifactSuffixes() []string {
+	return []string{c.diffSuffix()}
+}
+
+func (c Cmp) Equal(want, got any) error {
+	return c.equalWithArtifacts(want, got, nil)
+}
+
+func (c Cmp) equalWithArtifacts(want, got any, write artifactWriter) (err error) {
 	// cmp never returns errors and panics instead.
 	defer func() {
 		if r := recover(); r != nil {
@@ -50,9 +196,41 @@ func (c Cmp) Equal(want, got any) (err error) {
 		}
 	}
 
-	if diff := cmp.Diff(want, got, opts...); diff != "" {
-		return fmt.Errorf("%w (-want +got):\n%s", ErrValueDifference, diff)
+	if c.Compact {
+		var r pathCollector
+
+		if cmp.Equal(want, got, append(opts, cmp.Reporter(&r))...) {
+			return nil
+		}
+
+		return fmt.Errorf("%w at %d path(s):\n%s", ErrValueDifference, len(r.paths), strings.Join(r.paths, "\n"))
+	}
+
+	diff := cmp.Diff(want, got, opts...)
+	if diff == "" {
+		return nil
+	}
+
+	shown, omitted := truncateLines(diff, c.MaxDiffLines)
+
+	var truncated string
+
+	if omitted > 0 {
+		truncated = fmt.Sprintf("... %d more lines", omitted)
+
+		if write != nil {
+			if name, writeErr := write(c.diffSuffix(), []byte(diff)); writeErr != nil {
+				truncated += fmt.Sprintf("; writing full diff: %v", writeErr)
+			} else {
+				truncated += fmt.Sprintf("; full diff written to %q", name)
+			}
+		}
+
+		truncated += "\n"
 	}
 
-	return nil
+	return &diffError{
+		err:   fmt.Errorf("%w (-want +got):\n%s%s", ErrValueDifference, shown, truncated),
+		color: c.Color,
+	}
 }
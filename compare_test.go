@@ -2,6 +2,8 @@ package aurum
 
 import (
 	"errors"
+	"os"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -68,3 +70,173 @@ func TestCmp(t *testing.T) {
 		})
 	}
 }
+
+func TestCmpDiffOutput(t *testing.T) {
+	type value struct {
+		A, B, C, D string
+	}
+
+	want := value{A: "a", B: "b", C: "c", D: "d"}
+	got := value{A: "a", B: "x", C: "y", D: "z"}
+
+	for _, tc := range []struct {
+		name      string
+		compare   Cmp
+		write     bool
+		wantMsg   string
+		wantWrite string
+	}{
+		{
+			name:    "default",
+			compare: Cmp{Color: ColorNever},
+			wantMsg: `values are not equal (-want +got):
+  aurum.value{
+  	A: "a",
+- 	B: "b",
++ 	B: "x",
+- 	C: "c",
++ 	C: "y",
+- 	D: "d",
++ 	D: "z",
+  }
+`,
+		},
+		{
+			name:    "truncated",
+			compare: Cmp{Color: ColorNever, MaxDiffLines: 3},
+			wantMsg: `values are not equal (-want +got):
+  aurum.value{
+  	A: "a",
+- 	B: "b",
+... 6 more lines
+`,
+		},
+		{
+			name:    "truncated with file",
+			compare: Cmp{Color: ColorNever, MaxDiffLines: 3},
+			write:   true,
+			wantMsg: `values are not equal (-want +got):
+  aurum.value{
+  	A: "a",
+- 	B: "b",
+... 6 more lines; full diff written to "golden.diff.txt"
+`,
+			wantWrite: ".diff.txt",
+		},
+		{
+			name:    "not truncated",
+			compare: Cmp{Color: ColorNever, MaxDiffLines: 10},
+			write:   true,
+			wantMsg: `values are not equal (-want +got):
+  aurum.value{
+  	A: "a",
+- 	B: "b",
++ 	B: "x",
+- 	C: "c",
++ 	C: "y",
+- 	D: "d",
++ 	D: "z",
+  }
+`,
+		},
+		{
+			name:    "color not in error",
+			compare: Cmp{Color: ColorAlways, MaxDiffLines: 4},
+			wantMsg: `values are not equal (-want +got):
+  aurum.value{
+  	A: "a",
+- 	B: "b",
++ 	B: "x",
+... 5 more lines
+`,
+		},
+		{
+			name:    "compact",
+			compare: Cmp{Compact: true},
+			wantMsg: "values are not equal at 3 path(s):\nB\nC\nD",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotWrite string
+			var write artifactWriter
+
+			if tc.write {
+				write = func(suffix string, data []byte) (string, error) {
+					gotWrite = suffix
+
+					if !strings.Contains(strings.ReplaceAll(string(data), "\u00a0", " "), `+ 	D: "z",`) {
+						t.Errorf("Full diff is incomplete:\n%s", data)
+					}
+
+					return "golden" + suffix, nil
+				}
+			}
+
+			err := tc.compare.equalWithArtifacts(want, got, write)
+
+			if !errors.Is(err, ErrValueDifference) {
+				t.Fatalf("equalWithArtifacts() returned %v, want %v", err, ErrValueDifference)
+			}
+
+			// cmp randomly uses non-breaking spaces to discourage depending on
+			// the exact output.
+			gotMsg := strings.ReplaceAll(err.Error(), "\u00a0", " ")
+
+			if diff := cmp.Diff(tc.wantMsg, gotMsg); diff != "" {
+				t.Errorf("Message diff (-want +got):\n%s", diff)
+			}
+
+			if diff := cmp.Diff(tc.wantWrite, gotWrite); diff != "" {
+				t.Errorf("Written suffix diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestColorModeResolve(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		mode   ColorMode
+		global ColorMode
+		env    map[string]string
+		want   bool
+	}{
+		{name: "always", mode: ColorAlways, global: ColorNever, want: true},
+		{name: "never", mode: ColorNever, global: ColorAlways, env: map[string]string{"TERM": "xterm"}},
+		{name: "global always", global: ColorAlways, env: map[string]string{"NO_COLOR": ""}, want: true},
+		{name: "global never", global: ColorNever, env: map[string]string{"TERM": "xterm"}},
+		{name: "no terminal"},
+		{name: "terminal", env: map[string]string{"TERM": "xterm"}, want: true},
+		{name: "dumb terminal", env: map[string]string{"TERM": "dumb"}},
+		{name: "no color", env: map[string]string{"TERM": "xterm", "NO_COLOR": ""}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.mode.resolve(tc.global, func(name string) (string, bool) {
+				value, ok := tc.env[name]
+				return value, ok
+			})
+
+			if got != tc.want {
+				t.Errorf("resolve() returned %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestColorModeFlag(t *testing.T) {
+	var m ColorMode
+
+	f := colorModeFlag{&m}
+
+	for _, name := range []string{"always", "never", "auto"} {
+		if err := f.Set(name); err != nil {
+			t.Errorf("Set(%q) failed: %v", name, err)
+		} else if got := f.String(); got != name {
+			t.Errorf("String() returned %q, want %q", got, name)
+		}
+	}
+
+	if err := f.Set("unknown"); !errors.Is(err, os.ErrInvalid) {
+		t.Errorf("Set() returned %v, want %v", err, os.ErrInvalid)
+	}
+}
@@ -0,0 +1,369 @@
+package aurum
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/hansmi/aurum/internal/codecutil"
+)
+
+var stringTableType = reflect.TypeOf([][]string(nil))
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// CSVCodec stores tabular values using the CSV format via [encoding/csv].
+// Supported are [][]string and slices of structs or struct pointers.
+//
+// For structs the first record contains the column names. They're taken from
+// the "csv" struct tag if present and the field name otherwise. A tag value of
+// "-" excludes a field. Fields must be strings, booleans, numbers or implement
+// both [encoding.TextMarshaler] and [encoding.TextUnmarshaler].
+//
+// A nil slice is stored as an empty file. Empty [][]string values are
+// restored as nil.
+type CSVCodec struct {
+	// Field delimiter. Defaults to a comma. Use a tab character ('\t') for
+	// TSV files.
+	Comma rune
+
+	// Terminate lines with "\r\n" instead of "\n".
+	UseCRLF bool
+}
+
+var _ Codec = (*CSVCodec)(nil)
+
+type csvColumn struct {
+	name  string
+	index int
+}
+
+func csvColumns(t reflect.Type) ([]csvColumn, error) {
+	var result []csvColumn
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		if !f.IsExported() {
+			continue
+		}
+
+		name := f.Name
+
+		if tag, ok := f.Tag.Lookup("csv"); ok {
+			tag, _, _ = strings.Cut(tag, ",")
+
+			if tag == "-" {
+				continue
+			} else if tag != "" {
+				name = tag
+			}
+		}
+
+		if !csvSupportedType(f.Type) {
+			return nil, fmt.Errorf("%w: field %s of type %s is not supported", os.ErrInvalid, f.Name, f.Type)
+		}
+
+		result = append(result, csvColumn{name: name, index: i})
+	}
+
+	return result, nil
+}
+
+func csvSupportedType(t reflect.Type) bool {
+	if pt := reflect.PointerTo(t); pt.Implements(textMarshalerType) && pt.Implements(textUnmarshalerType) {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+
+	return false
+}
+
+// csvStructType returns the struct type of slice elements.
+func csvStructType(t reflect.Type) (reflect.Type, bool) {
+	if t.Kind() != reflect.Slice {
+		return nil, false
+	}
+
+	elem := t.Elem()
+
+	if elem.Kind() == reflect.Pointer {
+		elem = elem.Elem()
+	}
+
+	return elem, elem.Kind() == reflect.Struct
+}
+
+func csvFormatField(v reflect.Value) (string, error) {
+	if tm, ok := v.Addr().Interface().(encoding.TextMarshaler); ok {
+		text, err := tm.MarshalText()
+		return string(text), err
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), nil
+	}
+
+	return "", fmt.Errorf("%w: marshalling %s as CSV is not supported", os.ErrInvalid, v.Type())
+}
+
+func csvParseField(s string, v reflect.Value) error {
+	if tu, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return tu.UnmarshalText([]byte(s))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+		return nil
+
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err == nil {
+			v.SetBool(b)
+		}
+		return err
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err == nil {
+			v.SetInt(i)
+		}
+		return err
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err == nil {
+			v.SetUint(i)
+		}
+		return err
+
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err == nil {
+			v.SetFloat(f)
+		}
+		return err
+	}
+
+	return fmt.Errorf("%w: unmarshalling CSV into %s is not supported", os.ErrInvalid, v.Type())
+}
+
+func (c *CSVCodec) newWriter(buf *bytes.Buffer) *csv.Writer {
+	w := csv.NewWriter(buf)
+	w.UseCRLF = c.UseCRLF
+
+	if c.Comma != 0 {
+		w.Comma = c.Comma
+	}
+
+	return w
+}
+
+func (c *CSVCodec) newReader(data []byte) *csv.Reader {
+	r := csv.NewReader(bytes.NewReader(data))
+
+	if c.Comma != 0 {
+		r.Comma = c.Comma
+	}
+
+	return r
+}
+
+func (c *CSVCodec) marshalStructs(w *csv.Writer, rv reflect.Value, st reflect.Type) error {
+	columns, err := csvColumns(st)
+	if err != nil {
+		return err
+	}
+
+	record := make([]string, len(columns))
+
+	for i, col := range columns {
+		record[i] = col.name
+	}
+
+	if err := w.Write(record); err != nil {
+		return err
+	}
+
+	for idx := 0; idx < rv.Len(); idx++ {
+		elem := reflect.Indirect(rv.Index(idx))
+
+		// Rows are numbered starting at 1, excluding the header.
+		row := idx + 1
+
+		if !elem.IsValid() {
+			return fmt.Errorf("%w: row %d is nil", os.ErrInvalid, row)
+		}
+
+		for i, col := range columns {
+			if record[i], err = csvFormatField(elem.Field(col.index)); err != nil {
+				return fmt.Errorf("row %d, column %q: %w", row, col.name, err)
+			}
+		}
+
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *CSVCodec) Marshal(v any) ([]byte, error) {
+	rv, m, err := codecutil.PrepareMarshalValue(v)
+	if err != nil {
+		return nil, err
+	}
+
+	rv = reflect.Indirect(rv)
+
+	if m != nil || !rv.IsValid() {
+		return nil, fmt.Errorf("%w: marshalling %T as CSV is not supported", os.ErrInvalid, v)
+	}
+
+	var buf bytes.Buffer
+
+	w := c.newWriter(&buf)
+
+	if rv.Type() == stringTableType {
+		err = w.WriteAll(rv.Interface().([][]string))
+	} else if st, ok := csvStructType(rv.Type()); !ok {
+		return nil, fmt.Errorf("%w: marshalling %T as CSV is not supported", os.ErrInvalid, v)
+	} else if !rv.IsNil() {
+		err = c.marshalStructs(w, rv, st)
+	}
+
+	if err == nil {
+		w.Flush()
+		err = w.Error()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (c *CSVCodec) unmarshalStructs(r *csv.Reader, dest reflect.Value, st reflect.Type) error {
+	header, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		// Nil slices are stored as empty files.
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	columns, err := csvColumns(st)
+	if err != nil {
+		return err
+	}
+
+	byName := map[string]int{}
+
+	for _, col := range columns {
+		byName[col.name] = col.index
+	}
+
+	fields := make([]int, len(header))
+
+	for i, name := range header {
+		idx, ok := byName[name]
+		if !ok {
+			return fmt.Errorf("%w: unknown column %q for %s", os.ErrInvalid, name, st)
+		}
+
+		fields[i] = idx
+	}
+
+	records, err := r.ReadAll()
+	if err != nil {
+		return err
+	}
+
+	result := reflect.MakeSlice(dest.Type(), 0, len(records))
+
+	for row, record := range records {
+		elem := reflect.New(st)
+
+		for i, value := range record {
+			if err := csvParseField(value, elem.Elem().Field(fields[i])); err != nil {
+				return fmt.Errorf("row %d, column %q: %w", row+1, header[i], err)
+			}
+		}
+
+		if dest.Type().Elem().Kind() != reflect.Pointer {
+			elem = elem.Elem()
+		}
+
+		result = reflect.Append(result, elem)
+	}
+
+	dest.Set(result)
+
+	return nil
+}
+
+func (c *CSVCodec) Unmarshal(data []byte, v any) error {
+	rv, m, err := codecutil.PrepareUnmarshalDest(v)
+	if err != nil {
+		return err
+	}
+
+	if m != nil {
+		return fmt.Errorf("%w: unmarshalling CSV into %T is not supported", os.ErrInvalid, v)
+	}
+
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+
+		rv = rv.Elem()
+	}
+
+	r := c.newReader(data)
+
+	if rv.Type() == stringTableType {
+		r.FieldsPerRecord = -1
+
+		records, err := r.ReadAll()
+		if err != nil {
+			return err
+		}
+
+		rv.Set(reflect.ValueOf(records))
+
+		return nil
+	}
+
+	if st, ok := csvStructType(rv.Type()); ok {
+		return c.unmarshalStructs(r, rv, st)
+	}
+
+	return fmt.Errorf("%w: unmarshalling CSV into %T is not supported", os.ErrInvalid, v)
+}
@@ -0,0 +1,191 @@
+package aurum
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/hansmi/aurum/internal/codectest"
+	"github.com/hansmi/aurum/internal/ref"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type csvTestRow struct {
+	Name    string  `csv:"name"`
+	Count   int     `csv:"count"`
+	Ratio   float64 `csv:"ratio"`
+	Enabled bool    `csv:"enabled,omitempty"`
+	Size    uint16  `csv:"size"`
+	Time    time.Time
+	Ignored string `csv:"-"`
+}
+
+func TestCSVCodec(t *testing.T) {
+	rows := []csvTestRow{
+		{
+			Name:    "first, with comma",
+			Count:   -1,
+			Ratio:   0.25,
+			Enabled: true,
+			Size:    1024,
+			Time:    time.Date(2000, time.January, 1, 0, 1, 2, 3, time.UTC),
+		},
+		{
+			Name: "second\nwith \"quotes\"",
+		},
+	}
+
+	tests := []codectest.Case{
+		{
+			Name:  "string table",
+			Value: [][]string{{"a", "b"}, {"1"}, {"x", "y", "z"}},
+		},
+		{
+			Name:  "empty string table",
+			Value: [][]string{},
+		},
+		{
+			Name:  "structs",
+			Value: rows,
+		},
+		{
+			Name:  "struct pointers",
+			Value: []*csvTestRow{&rows[0], &rows[1]},
+		},
+		{
+			Name:  "empty structs",
+			Value: []csvTestRow{},
+		},
+		{
+			Name:  "nil structs",
+			Value: ref.Ref([]csvTestRow(nil)),
+		},
+		{
+			Name:           "string",
+			Value:          "hello world",
+			WantMarshalErr: os.ErrInvalid,
+		},
+		{
+			Name:           "unsupported field",
+			Value:          []struct{ Values []int }{{}},
+			WantMarshalErr: os.ErrInvalid,
+		},
+		{
+			Name:           "proto",
+			Value:          &wrapperspb.Int64Value{Value: 4321},
+			WantMarshalErr: os.ErrInvalid,
+		},
+	}
+
+	codectest.AssertAll(t, &CSVCodec{}, tests)
+
+	t.Run("TSV", func(t *testing.T) {
+		codectest.AssertAll(t, &CSVCodec{Comma: '\t', UseCRLF: true}, tests)
+	})
+}
+
+func TestCSVCodecMarshal(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		codec CSVCodec
+		value any
+		want  string
+	}{
+		{
+			name: "header",
+			value: []struct {
+				Name  string `csv:"name"`
+				Value float32
+			}{
+				{"a", 1.5},
+				{"b", 2},
+			},
+			want: "name,Value\na,1.5\nb,2\n",
+		},
+		{
+			name:  "tabs",
+			codec: CSVCodec{Comma: '\t'},
+			value: [][]string{{"a", "b c"}},
+			want:  "a\tb c\n",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.codec.Marshal(tc.value)
+			if err != nil {
+				t.Fatalf("Marshal() failed: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.want, string(got)); diff != "" {
+				t.Errorf("Marshal() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCSVCodecUnmarshal(t *testing.T) {
+	type row struct {
+		A int  `csv:"a"`
+		B bool `csv:"b"`
+	}
+
+	for _, tc := range []struct {
+		name    string
+		input   string
+		want    []row
+		wantErr error
+	}{
+		{name: "empty"},
+		{
+			name:  "reordered columns",
+			input: "b,a\ntrue,1\nfalse,2\n",
+			want:  []row{{1, true}, {2, false}},
+		},
+		{
+			name:    "unknown column",
+			input:   "a,c\n1,2\n",
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name:    "bad number",
+			input:   "a\nx\n",
+			wantErr: cmpopts.AnyError,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []row
+
+			err := (&CSVCodec{}).Unmarshal([]byte(tc.input), &got)
+
+			if diff := cmp.Diff(tc.wantErr, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("Error diff (-want +got):\n%s", diff)
+			}
+
+			if err == nil {
+				if diff := cmp.Diff(tc.want, got); diff != "" {
+					t.Errorf("Value diff (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestCSVCodecErrorRow(t *testing.T) {
+	type row struct {
+		A int
+	}
+
+	_, marshalErr := (&CSVCodec{}).Marshal([]*row{{1}, nil})
+
+	var got []row
+
+	unmarshalErr := (&CSVCodec{}).Unmarshal([]byte("A\n1\nx\n"), &got)
+
+	for _, err := range []error{marshalErr, unmarshalErr} {
+		if err == nil || !strings.Contains(err.Error(), "row 2") {
+			t.Errorf("Error %q doesn't refer to row 2", err)
+		}
+	}
+}
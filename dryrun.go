@@ -0,0 +1,93 @@
+package aurum
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+type changeKind int
+
+const (
+	changeUnchanged changeKind = iota
+	changeCreated
+	changeModified
+)
+
+func (k changeKind) String() string {
+	switch k {
+	case changeUnchanged:
+		return "unchanged"
+	case changeCreated:
+		return "created"
+	case changeModified:
+		return "modified"
+	}
+
+	return fmt.Sprintf("changeKind(%d)", int(k))
+}
+
+type plannedChange struct {
+	path    string
+	kind    changeKind
+	oldSize int
+	newSize int
+}
+
+// dryRunRecorder collects the changes an update would make.
+type dryRunRecorder struct {
+	mu      sync.Mutex
+	changes []plannedChange
+}
+
+func (r *dryRunRecorder) record(c plannedChange) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.changes = append(r.changes, c)
+}
+
+// writeSummary writes a human-readable summary of all planned changes.
+// Nothing is written if no changes were recorded.
+func (r *dryRunRecorder) writeSummary(w io.Writer) error {
+	r.mu.Lock()
+	changes := append([]plannedChange(nil), r.changes...)
+	r.mu.Unlock()
+
+	if len(changes) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].path < changes[j].path
+	})
+
+	counts := map[changeKind]int{}
+
+	for _, c := range changes {
+		counts[c.kind]++
+	}
+
+	if _, err := fmt.Fprintf(w, "Golden file update dry-run: %d created, %d modified, %d unchanged\n",
+		counts[changeCreated], counts[changeModified], counts[changeUnchanged]); err != nil {
+		return err
+	}
+
+	for _, c := range changes {
+		var err error
+
+		switch c.kind {
+		case changeCreated:
+			_, err = fmt.Fprintf(w, "  created   %s (%d bytes)\n", c.path, c.newSize)
+		case changeModified:
+			_, err = fmt.Fprintf(w, "  modified  %s (%d -> %d bytes, %+d)\n", c.path, c.oldSize, c.newSize, c.newSize-c.oldSize)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package aurum
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type fakeM struct {
+	code int
+	run  func()
+}
+
+func (m *fakeM) Run() int {
+	if m.run != nil {
+		m.run()
+	}
+
+	return m.code
+}
+
+func TestDryRun(t *testing.T) {
+	g := &globalOptions{
+		updatesEnabled: true,
+		dryRun:         true,
+	}
+
+	m := NewMemFS(map[string][]byte{
+		"unchanged": []byte("\"value\"\n"),
+		"modified":  []byte("\"value\"\n"),
+		"invalid":   []byte("{"),
+	})
+
+	o := &Golden{
+		g:  g,
+		FS: m,
+	}
+
+	var buf strings.Builder
+
+	code := g.runMain(&fakeM{
+		code: 3,
+		run: func() {
+			for name, value := range map[string]string{
+				"unchanged": "value",
+				"modified":  "modified value",
+				"invalid":   "",
+				"created":   "new",
+			} {
+				if err := o.assert(name, value, t.Logf); err != nil {
+					t.Errorf("assert(%q) failed: %v", name, err)
+				}
+			}
+
+			tb := &fakeTB{name: "snapshot"}
+			o.Snapshot(tb).Add("entry", 1)
+			tb.runCleanups()
+
+			if len(tb.errors) > 0 {
+				t.Errorf("Snapshot failed: %q", tb.errors)
+			}
+		},
+	}, &buf)
+
+	if code != 3 {
+		t.Errorf("runMain() returned %d, want 3", code)
+	}
+
+	if writes := m.Writes(); len(writes) > 0 {
+		t.Errorf("Files were written in dry-run mode: %+v", writes)
+	}
+
+	want := `Golden file assertions: 0 passed, 0 failed, 1 created, 0 updated, 0 reformatted
+  created  snapshot  snapshot
+Golden file update dry-run: 2 created, 2 modified, 1 unchanged
+  created   created (6 bytes)
+  modified  invalid (1 -> 3 bytes, +2)
+  modified  modified (8 -> 17 bytes, +9)
+  created   snapshot (17 bytes)
+`
+
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("Summary diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestDryRunDisabled(t *testing.T) {
+	g := &globalOptions{
+		updatesEnabled: true,
+	}
+
+	g.plan.record(plannedChange{path: "file", kind: changeCreated})
+
+	var buf strings.Builder
+
+	if code := g.runMain(&fakeM{}, &buf); code != 0 {
+		t.Errorf("runMain() returned %d, want 0", code)
+	}
+
+	if buf.Len() > 0 {
+		t.Errorf("Summary written without dry-run: %q", buf.String())
+	}
+}
@@ -0,0 +1,106 @@
+package aurum
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sync"
+)
+
+var errGoldenReused = errors.New("golden file asserted more than once")
+
+// goldenKey identifies a golden file independent of the [Golden] instance
+// used to access it.
+type goldenKey struct {
+	location any
+	filename string
+}
+
+// fsIdentity identifies a filesystem. Filesystems of reference types are
+// identified by their address.
+type fsIdentity struct {
+	t reflect.Type
+	p uintptr
+}
+
+// goldenLocation returns a comparable value identifying the storage location
+// of golden files. False is returned if no such value can be determined.
+func goldenLocation(o *Golden) (any, bool) {
+	if o.FS == nil {
+		dir, err := filepath.Abs(o.Dir)
+
+		return dir, err == nil
+	}
+
+	v := reflect.ValueOf(o.FS)
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return fsIdentity{v.Type(), v.Pointer()}, true
+	}
+
+	if v.Comparable() {
+		return o.FS, true
+	}
+
+	return nil, false
+}
+
+type goldenUse struct {
+	test string
+
+	// Hash of the marshalled value, avoiding a copy of the data.
+	digest [sha256.Size]byte
+}
+
+// useRegistry keeps track of the golden files asserted within a process.
+type useRegistry struct {
+	mu   sync.Mutex
+	uses map[goldenKey]goldenUse
+}
+
+// register records the use of a golden file by a test. Identical values
+// asserted repeatedly by the same test, e.g. when running with "-count", are
+// always accepted. Identical values from other tests are only accepted if
+// allowIdentical is set. An error naming both tests is returned otherwise.
+func (r *useRegistry) register(key goldenKey, path, test string, data []byte, allowIdentical bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	digest := sha256.Sum256(data)
+
+	prev, ok := r.uses[key]
+	if !ok {
+		if r.uses == nil {
+			r.uses = map[goldenKey]goldenUse{}
+		}
+
+		r.uses[key] = goldenUse{
+			test:   test,
+			digest: digest,
+		}
+
+		return nil
+	}
+
+	if prev.digest != digest {
+		return fmt.Errorf("%w: %s by tests %q and %q with different values", errGoldenReused, path, prev.test, test)
+	}
+
+	if prev.test == test || allowIdentical {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s by tests %q and %q", errGoldenReused, path, prev.test, test)
+}
+
+// testName returns the name of a test if available.
+func testName(tb TB) string {
+	if n, ok := tb.(interface{ Name() string }); ok {
+		return n.Name()
+	}
+
+	return ""
+}
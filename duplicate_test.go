@@ -0,0 +1,188 @@
+package aurum
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type uncomparableFS struct {
+	fstest.MapFS
+	extra []string
+}
+
+func TestGoldenLocation(t *testing.T) {
+	mapFS := fstest.MapFS{}
+	memFS := &MemFS{}
+
+	for _, tc := range []struct {
+		name     string
+		a, b     Golden
+		wantOK   bool
+		wantSame bool
+	}{
+		{
+			name:     "same dir",
+			a:        Golden{Dir: "testdata"},
+			b:        Golden{Dir: "./testdata/../testdata"},
+			wantOK:   true,
+			wantSame: true,
+		},
+		{
+			name:   "different dir",
+			a:      Golden{Dir: "testdata"},
+			b:      Golden{Dir: "other"},
+			wantOK: true,
+		},
+		{
+			name:     "same map",
+			a:        Golden{FS: mapFS},
+			b:        Golden{FS: mapFS},
+			wantOK:   true,
+			wantSame: true,
+		},
+		{
+			name:   "different map",
+			a:      Golden{FS: mapFS},
+			b:      Golden{FS: fstest.MapFS{}},
+			wantOK: true,
+		},
+		{
+			name:     "same pointer",
+			a:        Golden{FS: memFS},
+			b:        Golden{FS: memFS},
+			wantOK:   true,
+			wantSame: true,
+		},
+		{
+			name: "uncomparable",
+			a:    Golden{FS: uncomparableFS{}},
+			b:    Golden{FS: uncomparableFS{}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			a, okA := goldenLocation(&tc.a)
+			b, okB := goldenLocation(&tc.b)
+
+			if diff := cmp.Diff([]bool{tc.wantOK, tc.wantOK}, []bool{okA, okB}); diff != "" {
+				t.Errorf("goldenLocation() ok diff (-want +got):\n%s", diff)
+			}
+
+			if tc.wantOK {
+				if diff := cmp.Diff(tc.wantSame, a == b); diff != "" {
+					t.Errorf("Location equality diff (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestGoldenAssertDuplicate(t *testing.T) {
+	type assertion struct {
+		test    string
+		value   string
+		wantErr string
+	}
+
+	for _, tc := range []struct {
+		name       string
+		allowReuse bool
+		assertions []assertion
+	}{
+		{
+			name: "same test identical",
+			assertions: []assertion{
+				{test: "TestA", value: "content"},
+				{test: "TestA", value: "content"},
+			},
+		},
+		{
+			name: "same test different",
+			assertions: []assertion{
+				{test: "TestA", value: "content"},
+				{test: "TestA", value: "changed", wantErr: `"TestA" and "TestA" with different values`},
+			},
+		},
+		{
+			name: "other test identical",
+			assertions: []assertion{
+				{test: "TestA/first", value: "content"},
+				{test: "TestA/second", value: "content", wantErr: `"TestA/first" and "TestA/second"`},
+			},
+		},
+		{
+			name:       "other test identical allowed",
+			allowReuse: true,
+			assertions: []assertion{
+				{test: "TestA/first", value: "content"},
+				{test: "TestA/second", value: "content"},
+			},
+		},
+		{
+			name:       "other test different",
+			allowReuse: true,
+			assertions: []assertion{
+				{test: "TestA/first", value: "content"},
+				{test: "TestA/second", value: "changed", wantErr: `"TestA/first" and "TestA/second" with different values`},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := &globalOptions{
+				updatesEnabled: true,
+			}
+
+			dir := t.TempDir()
+
+			for idx, a := range tc.assertions {
+				// Use a new instance for every assertion.
+				o := &Golden{
+					g:                   g,
+					Dir:                 dir,
+					Codec:               &TextCodec{},
+					AllowIdenticalReuse: tc.allowReuse,
+				}
+
+				tb := &fakeTB{name: a.test}
+
+				o.Assert(tb, "value", a.value)
+
+				var got string
+
+				if len(tb.errors) > 0 {
+					got = strings.Join(tb.errors, "\n")
+				}
+
+				if (got == "") != (a.wantErr == "") || !strings.Contains(got, a.wantErr) {
+					t.Errorf("Assertion %d: got errors %q, want %q", idx, got, a.wantErr)
+				}
+
+				if a.wantErr != "" && !strings.Contains(got, filepath.Join(dir, "value")) {
+					t.Errorf("Assertion %d: error %q doesn't mention path", idx, got)
+				}
+			}
+		})
+	}
+}
+
+func TestUseRegistry(t *testing.T) {
+	var r useRegistry
+
+	key := goldenKey{"dir", "file"}
+
+	if err := r.register(key, "path", "TestA", []byte("a"), false); err != nil {
+		t.Errorf("register() failed: %v", err)
+	}
+
+	if err := r.register(goldenKey{"dir", "other"}, "path", "TestB", []byte("b"), false); err != nil {
+		t.Errorf("register() failed: %v", err)
+	}
+
+	if err := r.register(key, "path", "TestB", []byte("a"), false); !errors.Is(err, errGoldenReused) {
+		t.Errorf("register() returned %v, want %v", err, errGoldenReused)
+	}
+}
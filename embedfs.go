@@ -0,0 +1,34 @@
+package aurum
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+)
+
+// NewEmbedFS returns a filesystem reading golden files from the dir
+// subdirectory of an embedded filesystem. Updates are written to the same
+// subdirectory in the source tree, located relative to the source file calling
+// NewEmbedFS. The caller must therefore reside in the package declaring the
+// "//go:embed" directive.
+//
+//	//go:embed testdata
+//	var testdata embed.FS
+//
+//	var golden = aurum.Golden{
+//		FS: aurum.NewEmbedFS(testdata, "testdata"),
+//	}
+//
+// NewEmbedFS panics if dir is not a valid path (see [fs.ValidPath]).
+func NewEmbedFS(efs embed.FS, dir string) *OverlayFS {
+	sub, err := fs.Sub(efs, dir)
+	if err != nil {
+		panic(fmt.Sprintf("aurum: embedded directory: %v", err))
+	}
+
+	return &OverlayFS{
+		Read:  sub,
+		Write: newWritableDirFS(filepath.Join(callerDir(1), filepath.FromSlash(dir))),
+	}
+}
@@ -0,0 +1,48 @@
+package aurum
+
+import (
+	"embed"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+//go:embed testdata/embed
+var testEmbedFS embed.FS
+
+func TestNewEmbedFS(t *testing.T) {
+	f := NewEmbedFS(testEmbedFS, "testdata/embed")
+
+	if got, err := fs.ReadFile(f, "value"); err != nil {
+		t.Errorf("ReadFile() failed: %v", err)
+	} else if diff := cmp.Diff("embedded\n", string(got)); diff != "" {
+		t.Errorf("ReadFile() diff (-want +got):\n%s", diff)
+	}
+
+	w, ok := f.Write.(*writableDirFS)
+	if !ok {
+		t.Fatalf("Unexpected write filesystem %#v", f.Write)
+	}
+
+	// Tests run in the package directory.
+	if got, err := os.Stat(w.dir); err != nil {
+		t.Errorf("Stat() failed: %v", err)
+	} else if want, err := os.Stat(filepath.Join("testdata", "embed")); err != nil {
+		t.Errorf("Stat() failed: %v", err)
+	} else if !os.SameFile(want, got) {
+		t.Errorf("Write directory %q doesn't refer to source directory", w.dir)
+	}
+}
+
+func TestNewEmbedFSInvalidDir(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("NewEmbedFS() didn't panic")
+		}
+	}()
+
+	NewEmbedFS(testEmbedFS, "../invalid")
+}
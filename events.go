@@ -0,0 +1,123 @@
+package aurum
+
+// GoldenReadEvent is reported after reading a golden file.
+type GoldenReadEvent struct {
+	// Name of the test making the assertion. Empty if unknown.
+	Test string
+
+	// Path of the golden file.
+	Path string
+
+	// Number of bytes read.
+	Size int
+
+	// Error encountered while reading, e.g. because the file is missing.
+	Err error
+}
+
+// ComparisonEvent is reported after comparing a value with the value stored
+// in a golden file.
+type ComparisonEvent struct {
+	Test string
+	Path string
+
+	// Whether the values are equal.
+	Equal bool
+
+	// Description of the difference if the values aren't equal.
+	Diff string
+}
+
+// UnmarshalFailedEvent is reported when the content of a golden file can't
+// be unmarshalled, e.g. because it's corrupt or of an outdated format.
+type UnmarshalFailedEvent struct {
+	Test string
+	Path string
+	Err  error
+}
+
+// UpdateWrittenEvent is reported after writing a golden file.
+type UpdateWrittenEvent struct {
+	Test string
+	Path string
+
+	// Size of the previous content. Zero for newly created files.
+	OldSize int
+
+	// Size of the written content.
+	NewSize int
+
+	// Whether the golden file didn't exist before.
+	Created bool
+
+	// Whether the update was only recorded in dry-run mode.
+	DryRun bool
+
+	// Name of the codec (e.g. "json" or "textproto").
+	Codec string
+
+	// Previous content. Nil for newly created files.
+	Old []byte
+
+	// Written content.
+	New []byte
+}
+
+// UpdateSkippedEvent is reported when a golden file would have to be
+// written, but updates are disabled.
+type UpdateSkippedEvent struct {
+	Test string
+	Path string
+
+	// Reason for the skipped update.
+	Reason string
+
+	// Name of the codec (e.g. "json" or "textproto").
+	Codec string
+
+	// Current content. Nil for missing files.
+	Old []byte
+
+	// Content which would have been written.
+	New []byte
+}
+
+func (GoldenReadEvent) isEvent()      {}
+func (ComparisonEvent) isEvent()      {}
+func (UnmarshalFailedEvent) isEvent() {}
+func (UpdateWrittenEvent) isEvent()   {}
+func (UpdateSkippedEvent) isEvent()   {}
+
+// report passes an event to the reporter of the instance and to all globally
+// configured reporters.
+func (o *Golden) report(e Event) {
+	if o.Reporter != nil {
+		o.Reporter.Report(e)
+	}
+
+	o.g.reporters.report(e)
+}
+
+// reportSkipped reports an update which wasn't made because updates are
+// disabled.
+func (o *Golden) reportSkipped(filename, reason string, previous, data []byte) {
+	o.report(UpdateSkippedEvent{
+		Test:   o.test,
+		Path:   o.displayPath(filename),
+		Reason: reason,
+		Codec:  o.codecName(),
+		Old:    previous,
+		New:    data,
+	})
+}
+
+// codecName returns the name of the codec selected for a golden file.
+func (o *Golden) codecName() string {
+	c := o.Codec
+
+	if h, ok := c.(*headerCodec); ok {
+		c = h.inner
+	}
+
+	return CodecName(c)
+}
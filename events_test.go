@@ -0,0 +1,106 @@
+package aurum
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestGoldenReporterEvents(t *testing.T) {
+	for _, tc := range []struct {
+		name           string
+		files          map[string][]byte
+		value          string
+		updatesEnabled bool
+		dryRun         bool
+		want           []Event
+	}{
+		{
+			name:           "created",
+			value:          "value",
+			updatesEnabled: true,
+			want: []Event{
+				GoldenReadEvent{Test: "TestEvents", Path: "file", Err: cmpopts.AnyError},
+				UpdateWrittenEvent{Test: "TestEvents", Path: "file", NewSize: 8, Created: true, Codec: "json", New: []byte("\"value\"\n")},
+				AssertionResult{Test: "TestEvents", Path: "file", Outcome: OutcomeCreated},
+			},
+		},
+		{
+			name:  "missing",
+			value: "value",
+			want: []Event{
+				GoldenReadEvent{Test: "TestEvents", Path: "file", Err: cmpopts.AnyError},
+				UpdateSkippedEvent{Test: "TestEvents", Path: "file", Reason: updatesDisabledReason, Codec: "json", New: []byte("\"value\"\n")},
+				AssertionResult{Test: "TestEvents", Path: "file", Outcome: OutcomeFailed},
+			},
+		},
+		{
+			name: "difference",
+			files: map[string][]byte{
+				"file": []byte("\"value\"\n"),
+			},
+			value: "changed",
+			want: []Event{
+				GoldenReadEvent{Test: "TestEvents", Path: "file", Size: 8},
+				ComparisonEvent{Test: "TestEvents", Path: "file"},
+				UpdateSkippedEvent{Test: "TestEvents", Path: "file", Reason: updatesDisabledReason, Codec: "json", Old: []byte("\"value\"\n"), New: []byte("\"changed\"\n")},
+				AssertionResult{Test: "TestEvents", Path: "file", Outcome: OutcomeFailed},
+			},
+		},
+		{
+			name: "dry-run update",
+			files: map[string][]byte{
+				"file": []byte("\"value\"\n"),
+			},
+			value:          "changed",
+			updatesEnabled: true,
+			dryRun:         true,
+			want: []Event{
+				GoldenReadEvent{Test: "TestEvents", Path: "file", Size: 8},
+				ComparisonEvent{Test: "TestEvents", Path: "file"},
+				UpdateWrittenEvent{Test: "TestEvents", Path: "file", OldSize: 8, NewSize: 10, DryRun: true, Codec: "json", Old: []byte("\"value\"\n"), New: []byte("\"changed\"\n")},
+				AssertionResult{Test: "TestEvents", Path: "file", Outcome: OutcomeUpdated},
+			},
+		},
+		{
+			name: "unmarshal failure",
+			files: map[string][]byte{
+				"file": []byte("{"),
+			},
+			value:          "value",
+			updatesEnabled: true,
+			want: []Event{
+				GoldenReadEvent{Test: "TestEvents", Path: "file", Size: 1},
+				UnmarshalFailedEvent{Test: "TestEvents", Path: "file", Err: cmpopts.AnyError},
+				UpdateWrittenEvent{Test: "TestEvents", Path: "file", OldSize: 1, NewSize: 8, Codec: "json", Old: []byte("{"), New: []byte("\"value\"\n")},
+				AssertionResult{Test: "TestEvents", Path: "file", Outcome: OutcomeUpdated},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &fakeReporter{}
+
+			o := &Golden{
+				g: &globalOptions{
+					updatesEnabled: tc.updatesEnabled,
+					dryRun:         tc.dryRun,
+				},
+				FS:       NewMemFS(tc.files),
+				Reporter: r,
+			}
+
+			o.Assert(&fakeTB{name: "TestEvents"}, "file", tc.value)
+
+			opts := []cmp.Option{
+				cmpopts.EquateErrors(),
+				cmpopts.IgnoreFields(ComparisonEvent{}, "Diff"),
+				cmpopts.IgnoreFields(AssertionResult{}, "Message"),
+			}
+
+			if diff := cmp.Diff(tc.want, r.events, opts...); diff != "" {
+				t.Errorf("Events diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
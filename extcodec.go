@@ -0,0 +1,124 @@
+package aurum
+
+import (
+	"strings"
+	"sync"
+)
+
+// codecSelector is implemented by codecs delegating to another codec
+// depending on the golden file name.
+type codecSelector interface {
+	CodecFor(name string) Codec
+}
+
+// ExtensionCodec selects a codec based on the extension of the golden file
+// name. This allows a single [Golden] to manage files of different formats.
+// The following extensions are registered by default:
+//
+//   - ".json": [JSONCodec]
+//   - ".textproto", ".txtpb": [TextProtoCodec]
+//   - ".txt", ".html": [TextCodec]
+//   - ".xml": [XMLCodec]
+//   - ".csv": [CSVCodec]
+//   - ".tsv": [CSVCodec] with tabs as separators
+//   - ".png": [ImageCodec]
+//
+// The zero value is ready for use. Direct calls to Marshal and Unmarshal use
+// the default codec.
+type ExtensionCodec struct {
+	// Codec for names without a registered extension.
+	//
+	// Defaults to [JSONCodec].
+	Default Codec
+
+	mu     sync.RWMutex
+	codecs map[string]Codec
+}
+
+var _ Codec = (*ExtensionCodec)(nil)
+var _ codecSelector = (*ExtensionCodec)(nil)
+
+func builtinExtensionCodecs() map[string]Codec {
+	return map[string]Codec{
+		".json":      &JSONCodec{},
+		".textproto": &TextProtoCodec{},
+		".txtpb":     &TextProtoCodec{},
+		".txt":       &TextCodec{},
+		".html":      &TextCodec{},
+		".xml":       &XMLCodec{},
+		".csv":       &CSVCodec{},
+		".tsv":       &CSVCodec{Comma: '\t'},
+		".png":       &ImageCodec{},
+	}
+}
+
+func normalizeExtension(ext string) string {
+	ext = strings.ToLower(ext)
+
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+
+	return ext
+}
+
+// Register sets the codec for an extension, replacing any previous mapping.
+// The extension is matched case-insensitively and may consist of multiple
+// parts, e.g. ".json.gz". The longest matching extension takes precedence.
+// Registering a nil codec removes the mapping.
+func (c *ExtensionCodec) Register(ext string, codec Codec) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.codecs == nil {
+		c.codecs = builtinExtensionCodecs()
+	}
+
+	c.codecs[normalizeExtension(ext)] = codec
+}
+
+func (c *ExtensionCodec) defaultCodec() Codec {
+	if c.Default == nil {
+		return &JSONCodec{}
+	}
+
+	return c.Default
+}
+
+// CodecFor returns the codec for the given file name.
+func (c *ExtensionCodec) CodecFor(name string) Codec {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	codecs := c.codecs
+
+	if codecs == nil {
+		codecs = builtinExtensionCodecs()
+	}
+
+	name = strings.ToLower(name)
+
+	var result Codec
+	var resultExt string
+
+	for ext, codec := range codecs {
+		if codec != nil && len(ext) > len(resultExt) && len(name) > len(ext) && strings.HasSuffix(name, ext) {
+			result = codec
+			resultExt = ext
+		}
+	}
+
+	if result == nil {
+		return c.defaultCodec()
+	}
+
+	return result
+}
+
+func (c *ExtensionCodec) Marshal(v any) ([]byte, error) {
+	return c.defaultCodec().Marshal(v)
+}
+
+func (c *ExtensionCodec) Unmarshal(data []byte, v any) error {
+	return c.defaultCodec().Unmarshal(data, v)
+}
@@ -0,0 +1,100 @@
+package aurum
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/hansmi/aurum/internal/ref"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestExtensionCodec(t *testing.T) {
+	var c ExtensionCodec
+
+	gzipJSON := &GzipCodec{Inner: &JSONCodec{}}
+
+	c.Register("json.gz", gzipJSON)
+	c.Register(".HTML", nil)
+
+	for _, tc := range []struct {
+		name string
+		want Codec
+	}{
+		{name: "", want: &JSONCodec{}},
+		{name: "noext", want: &JSONCodec{}},
+		{name: ".json", want: &JSONCodec{}},
+		{name: "value.json", want: &JSONCodec{}},
+		{name: "value.JSON", want: &JSONCodec{}},
+		{name: "value.textproto", want: &TextProtoCodec{}},
+		{name: "value.txtpb", want: &TextProtoCodec{}},
+		{name: "value.txt", want: &TextCodec{}},
+		{name: "value.html", want: &JSONCodec{}},
+		{name: "value.tsv", want: &CSVCodec{Comma: '\t'}},
+		{name: "value.json.gz", want: gzipJSON},
+		{name: "value.gz", want: &JSONCodec{}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := c.CodecFor(tc.name)
+
+			if diff := cmp.Diff(tc.want, got, cmpopts.IgnoreUnexported(
+				protojson.MarshalOptions{},
+				protojson.UnmarshalOptions{},
+				prototext.MarshalOptions{},
+				prototext.UnmarshalOptions{},
+			)); diff != "" {
+				t.Errorf("CodecFor(%q) diff (-want +got):\n%s", tc.name, diff)
+			}
+		})
+	}
+}
+
+func TestExtensionCodecDefault(t *testing.T) {
+	c := ExtensionCodec{
+		Default: &TextCodec{},
+	}
+
+	if diff := cmp.Diff(&TextCodec{}, c.CodecFor("file.unknown")); diff != "" {
+		t.Errorf("CodecFor() diff (-want +got):\n%s", diff)
+	}
+
+	data, err := c.Marshal(ref.Ref("text"))
+	if err != nil {
+		t.Errorf("Marshal() failed: %v", err)
+	}
+
+	if diff := cmp.Diff("text", string(data)); diff != "" {
+		t.Errorf("Marshal() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestGoldenAssertExtensionCodec(t *testing.T) {
+	o := &Golden{
+		g:     &globalOptions{},
+		Codec: &ExtensionCodec{},
+		FS: fstest.MapFS{
+			"value.json": {
+				Data: []byte(`"4321"`),
+			},
+			"value.textproto": {
+				Data: []byte(`value: 4321`),
+			},
+			"value.txt": {
+				Data: []byte(`4321`),
+			},
+		},
+	}
+
+	for _, name := range []string{"value.json", "value.textproto"} {
+		if err := o.assert(name, &wrapperspb.Int64Value{Value: 4321}, t.Logf); err != nil {
+			t.Errorf("assert(%q) failed: %v", name, err)
+		}
+	}
+
+	if err := o.assert("value.txt", "4321", t.Logf); err != nil {
+		t.Errorf("assert() failed: %v", err)
+	}
+}
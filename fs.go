@@ -3,6 +3,7 @@ package aurum
 import (
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 )
 
@@ -16,6 +17,7 @@ type writableDirFS struct {
 }
 
 var _ WriteFileFS = (*writableDirFS)(nil)
+var _ RemoveFS = (*writableDirFS)(nil)
 
 func newWritableDirFS(dir string) *writableDirFS {
 	return &writableDirFS{
@@ -24,6 +26,81 @@ func newWritableDirFS(dir string) *writableDirFS {
 	}
 }
 
+func (f *writableDirFS) lockPath(name string) (string, error) {
+	return filepath.Abs(filepath.Join(f.dir, name))
+}
+
 func (f *writableDirFS) WriteFile(name string, data []byte, perm os.FileMode) error {
+	if dir := path.Dir(name); dir != "." {
+		// Names with slashes are only used by layouts with subdirectories,
+		// e.g. LegacyFS.
+		if err := os.MkdirAll(filepath.Join(f.dir, filepath.FromSlash(dir)), 0o755); err != nil {
+			return err
+		}
+	}
+
 	return os.WriteFile(filepath.Join(f.dir, name), data, perm)
 }
+
+func (f *writableDirFS) Remove(name string) error {
+	return os.Remove(filepath.Join(f.dir, name))
+}
+
+// artifactFS returns the writer for files supplementing golden files, e.g.
+// visual diffs. Only plain directories are supported. Other filesystems may
+// store golden files in a different layout or within a single file, and
+// artifacts must never become part of it.
+func artifactFS(fsys fs.FS) *writableDirFS {
+	switch f := fsys.(type) {
+	case *writableDirFS:
+		return f
+	case *OverlayFS:
+		if w, ok := f.Write.(*writableDirFS); ok {
+			return w
+		}
+	}
+
+	return nil
+}
+
+// OverlayFS reads files from one filesystem and writes them to another. It's
+// useful in hermetic build systems where the source directory is read-only
+// and updates must be written to a different location.
+//
+// Written files are not visible through the read filesystem unless both
+// refer to the same storage.
+type OverlayFS struct {
+	// Filesystem for reading files.
+	Read fs.FS
+
+	// Filesystem receiving all writes.
+	Write WriteFileFS
+}
+
+var _ fs.FS = (*OverlayFS)(nil)
+var _ WriteFileFS = (*OverlayFS)(nil)
+
+// NewDirOverlayFS returns a filesystem reading from one directory and writing
+// to another.
+func NewDirOverlayFS(readDir, writeDir string) *OverlayFS {
+	return &OverlayFS{
+		Read:  os.DirFS(readDir),
+		Write: newWritableDirFS(writeDir),
+	}
+}
+
+func (f *OverlayFS) Open(name string) (fs.File, error) {
+	return f.Read.Open(name)
+}
+
+func (f *OverlayFS) lockPath(name string) (string, error) {
+	if lp, ok := f.Write.(lockPather); ok {
+		return lp.lockPath(name)
+	}
+
+	return "", nil
+}
+
+func (f *OverlayFS) WriteFile(name string, data []byte, perm os.FileMode) error {
+	return f.Write.WriteFile(name, data, perm)
+}
@@ -1,9 +1,12 @@
 package aurum
 
 import (
+	"io/fs"
+	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/hansmi/aurum/internal/testutil"
 )
 
@@ -18,3 +21,36 @@ func TestWritableDirFS(t *testing.T) {
 
 	testutil.MustLstat(t, filepath.Join(tmpdir, "test1"))
 }
+
+func TestOverlayFS(t *testing.T) {
+	readDir := t.TempDir()
+	writeDir := t.TempDir()
+
+	testutil.MustWriteFile(t, filepath.Join(readDir, "source"), "content")
+
+	f := NewDirOverlayFS(readDir, writeDir)
+
+	if got, err := fs.ReadFile(f, "source"); err != nil {
+		t.Errorf("ReadFile() failed: %v", err)
+	} else if diff := cmp.Diff("content", string(got)); diff != "" {
+		t.Errorf("ReadFile() diff (-want +got):\n%s", diff)
+	}
+
+	if err := f.WriteFile("source", []byte("changed"), 0o644); err != nil {
+		t.Errorf("WriteFile() failed: %v", err)
+	}
+
+	for _, tc := range []struct {
+		path string
+		want string
+	}{
+		{filepath.Join(readDir, "source"), "content"},
+		{filepath.Join(writeDir, "source"), "changed"},
+	} {
+		if got, err := os.ReadFile(tc.path); err != nil {
+			t.Errorf("ReadFile(%q) failed: %v", tc.path, err)
+		} else if diff := cmp.Diff(tc.want, string(got)); diff != "" {
+			t.Errorf("File %q diff (-want +got):\n%s", tc.path, diff)
+		}
+	}
+}
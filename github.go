@@ -0,0 +1,91 @@
+package aurum
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// GitHubReporter writes GitHub Actions workflow commands annotating golden
+// files of failed assertions. Pull request views then link directly to the
+// affected golden files.
+//
+// See https://docs.github.com/en/actions/using-workflows/workflow-commands-for-github-actions.
+type GitHubReporter struct {
+	// Destination for workflow commands. Must be the standard output of the
+	// step.
+	W io.Writer
+
+	// Directory against which golden file paths are made relative, usually the
+	// repository root. Paths are left unchanged if empty.
+	BaseDir string
+
+	mu sync.Mutex
+}
+
+var _ Reporter = (*GitHubReporter)(nil)
+
+// NewGitHubReporter returns a reporter writing to standard output with paths
+// relative to the workspace directory of the workflow run
+// ("GITHUB_WORKSPACE").
+func NewGitHubReporter() *GitHubReporter {
+	return &GitHubReporter{
+		W:       os.Stdout,
+		BaseDir: os.Getenv("GITHUB_WORKSPACE"),
+	}
+}
+
+var githubDataEscaper = strings.NewReplacer(
+	"%", "%25",
+	"\r", "%0D",
+	"\n", "%0A",
+)
+
+var githubPropertyEscaper = strings.NewReplacer(
+	"%", "%25",
+	"\r", "%0D",
+	"\n", "%0A",
+	":", "%3A",
+	",", "%2C",
+)
+
+func (r *GitHubReporter) relPath(path string) string {
+	if r.BaseDir == "" {
+		return path
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+
+	if rel, err := filepath.Rel(r.BaseDir, abs); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.ToSlash(rel)
+	}
+
+	return path
+}
+
+func (r *GitHubReporter) Report(e Event) {
+	result, ok := e.(AssertionResult)
+	if !ok || result.Outcome != OutcomeFailed {
+		return
+	}
+
+	title := "Golden file assertion failed"
+
+	if result.Test != "" {
+		title += " in " + result.Test
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fmt.Fprintf(r.W, "::error file=%s,title=%s::%s\n",
+		githubPropertyEscaper.Replace(r.relPath(result.Path)),
+		githubPropertyEscaper.Replace(title),
+		githubDataEscaper.Replace(result.Message))
+}
@@ -0,0 +1,37 @@
+package aurum
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGitHubReporter(t *testing.T) {
+	base := t.TempDir()
+
+	var buf strings.Builder
+
+	r := &GitHubReporter{
+		W:       &buf,
+		BaseDir: base,
+	}
+
+	r.Report(AssertionResult{Test: "TestA", Path: "testdata/a", Outcome: OutcomePassed})
+	r.Report(AssertionResult{Test: "TestA", Path: "testdata/b", Outcome: OutcomeUpdated})
+	r.Report(AssertionResult{
+		Test:    "TestB/x,y",
+		Path:    filepath.Join(base, "pkg", "testdata", "c"),
+		Outcome: OutcomeFailed,
+		Message: "values differ:\n-a\n+b 100%",
+	})
+	r.Report(AssertionResult{Path: "outside", Outcome: OutcomeFailed, Message: "missing"})
+
+	want := "::error file=pkg/testdata/c,title=Golden file assertion failed in TestB/x%2Cy::values differ:%0A-a%0A+b 100%25\n" +
+		"::error file=outside,title=Golden file assertion failed::missing\n"
+
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("Output diff (-want +got):\n%s", diff)
+	}
+}
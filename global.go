@@ -3,17 +3,135 @@ package aurum
 import (
 	"errors"
 	"flag"
+	"os"
+	"strconv"
+	"strings"
 	"sync"
 )
 
 const DefaultUpdateFlagName = "update_golden_files"
 
+// Default name of the flag for the write root directory (see [WithWriteRoot]).
+const DefaultWriteRootFlagName = "update_golden_files_root"
+
+// Default name of the environment variable for the write root directory (see
+// [WithWriteRoot]).
+const DefaultWriteRootEnv = "AURUM_UPDATE_ROOT"
+
+// Default name of the flag for coloring diffs (see [ColorMode]).
+const DefaultColorFlagName = "golden_diff_color"
+
+// Value of the update flag enabling the dry-run mode.
+const updateFlagDryRun = "dryrun"
+
 type globalOptions struct {
 	mu             sync.Mutex
 	initialized    bool
 	flagSet        *flag.FlagSet
 	flagName       string
 	updatesEnabled bool
+
+	// Decide about updates without writing files. Requires updatesEnabled.
+	dryRun bool
+
+	// Changes recorded in dry-run mode.
+	plan dryRunRecorder
+
+	// Directory against which relative golden directories are resolved when
+	// writing updates.
+	writeRoot         string
+	writeRootFlagName string
+	writeRootEnv      string
+
+	// Golden files asserted so far.
+	uses useRegistry
+
+	// Outcomes of all assertions for the end-of-run summary.
+	results resultCollector
+
+	// Coloring of diffs for comparers using [ColorAuto].
+	colorMode     ColorMode
+	colorFlagName string
+
+	// Receivers of assertion events.
+	reporters reporterList
+
+	// Write the assertion results as JSON to this file in [Main].
+	summaryJSON string
+
+	// Serialize updates of golden files within the process.
+	locks pathLocks
+
+	// Lock golden file directories during updates to serialize updates across
+	// processes.
+	fileLocking bool
+
+	// Ask before writing each update. Requires updatesEnabled.
+	interactive bool
+
+	// File with decisions for interactive updates.
+	decisionsFile     string
+	decisionsFlagName string
+
+	// Created on first use in interactive mode.
+	decider updateDecider
+}
+
+// updateFlag is a boolean flag additionally accepting "dryrun" and
+// "interactive" as values.
+type updateFlag struct {
+	g *globalOptions
+}
+
+var _ flag.Value = (*updateFlag)(nil)
+
+func (f *updateFlag) IsBoolFlag() bool {
+	return true
+}
+
+func (f *updateFlag) String() string {
+	if f.g == nil {
+		return ""
+	}
+
+	if f.g.dryRun {
+		return updateFlagDryRun
+	}
+
+	if f.g.interactive {
+		return updateFlagInteractive
+	}
+
+	return strconv.FormatBool(f.g.updatesEnabled)
+}
+
+func (f *updateFlag) Set(value string) error {
+	switch value {
+	case updateFlagDryRun:
+		f.g.updatesEnabled = true
+		f.g.dryRun = true
+		f.g.interactive = false
+
+		return nil
+
+	case updateFlagInteractive:
+		f.g.updatesEnabled = true
+		f.g.dryRun = false
+		f.g.interactive = true
+
+		return nil
+	}
+
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return err
+	}
+
+	f.g.updatesEnabled = enabled
+	f.g.dryRun = false
+	f.g.interactive = false
+
+	return nil
 }
 
 func (g *globalOptions) init(opts []InitOption) error {
@@ -33,8 +151,24 @@ func (g *globalOptions) init(opts []InitOption) error {
 	}
 
 	if g.flagName != "" {
-		g.flagSet.BoolVar(&g.updatesEnabled, g.flagName, g.updatesEnabled,
-			"Update golden test files in-place.")
+		g.flagSet.Var(&updateFlag{g}, g.flagName,
+			`Update golden test files in-place. Use "`+updateFlagDryRun+`" to only report the changes which would be made or "`+
+				updateFlagInteractive+`" to confirm each change.`)
+	}
+
+	if g.decisionsFlagName != "" {
+		g.flagSet.StringVar(&g.decisionsFile, g.decisionsFlagName, g.decisionsFile,
+			`File with decisions for interactive updates, one "accept PATTERN" or "reject PATTERN" per line.`)
+	}
+
+	if g.writeRootFlagName != "" {
+		g.flagSet.StringVar(&g.writeRoot, g.writeRootFlagName, g.writeRoot,
+			"Write updated golden files to a directory tree rooted at the given path instead of the working directory.")
+	}
+
+	if g.colorFlagName != "" {
+		g.flagSet.Var(colorModeFlag{&g.colorMode}, g.colorFlagName,
+			`Color differences in golden values ("auto", "always" or "never").`)
 	}
 
 	g.initialized = true
@@ -49,8 +183,67 @@ func (g *globalOptions) checkUpdatesEnabled() bool {
 	return g.updatesEnabled
 }
 
+func (g *globalOptions) checkDryRunEnabled() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.updatesEnabled && g.dryRun
+}
+
+func (g *globalOptions) checkColorMode() ColorMode {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.colorMode
+}
+
+func (g *globalOptions) checkFileLockingEnabled() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.fileLocking
+}
+
+// checkSubtestFilter returns whether the test flags ("-test.run" and
+// "-test.skip") may exclude some subtests. Tests may then only run partially.
+func (g *globalOptions) checkSubtestFilter() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.flagSet == nil {
+		return false
+	}
+
+	for _, name := range []string{"test.run", "test.skip"} {
+		if f := g.flagSet.Lookup(name); f != nil && strings.Contains(f.Value.String(), "/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkWriteRoot returns the directory against which relative golden
+// directories are resolved when writing updates. An empty string is returned
+// if writes should use the working directory.
+func (g *globalOptions) checkWriteRoot() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.writeRoot == "" && g.writeRootEnv != "" {
+		return os.Getenv(g.writeRootEnv)
+	}
+
+	return g.writeRoot
+}
+
 var global = &globalOptions{
-	flagName: DefaultUpdateFlagName,
+	flagSet:           flag.CommandLine,
+	flagName:          DefaultUpdateFlagName,
+	writeRootFlagName: DefaultWriteRootFlagName,
+	writeRootEnv:      DefaultWriteRootEnv,
+	colorFlagName:     DefaultColorFlagName,
+	decisionsFlagName: DefaultDecisionsFlagName,
 }
 
 // Interface implemented by initialization options.
@@ -69,6 +262,134 @@ func WithFlagName(name string) InitOption {
 	return withFlagName(name)
 }
 
+type withWriteRoot string
+
+func (d withWriteRoot) apply(opt *globalOptions) {
+	opt.writeRoot = string(d)
+}
+
+// Write updated golden files to a directory tree rooted at the given path.
+// Relative golden directories are resolved against the root instead of the
+// working directory while reads continue to use the working directory. The
+// root corresponds to the module root, i.e. the nearest directory containing
+// a "go.mod" file, and "testdata" of package "pkg" is written to
+// "ROOT/pkg/testdata". Useful in hermetic build systems where the source tree
+// is read-only, e.g. with the BUILD_WORKSPACE_DIRECTORY environment variable
+// set by Bazel.
+//
+// The root can also be set using a command line flag (see
+// [DefaultWriteRootFlagName]) or an environment variable (see
+// [DefaultWriteRootEnv]).
+func WithWriteRoot(dir string) InitOption {
+	return withWriteRoot(dir)
+}
+
+type withFileLocking bool
+
+func (l withFileLocking) apply(opt *globalOptions) {
+	opt.fileLocking = bool(l)
+}
+
+// Acquire an advisory lock (flock) on the directory containing a golden file
+// while it's being updated. Prevents concurrently running test binaries, e.g.
+// from "go test ./...", from interleaving updates of shared golden files.
+// Updates within a process are always serialized. Not supported on all
+// platforms.
+func WithFileLocking(enabled bool) InitOption {
+	return withFileLocking(enabled)
+}
+
+type withSummaryJSON string
+
+func (p withSummaryJSON) apply(opt *globalOptions) {
+	opt.summaryJSON = string(p)
+}
+
+// Write the results of all assertions as a JSON document to the given file
+// when the tests finish. Requires the use of [Main].
+func WithSummaryJSON(path string) InitOption {
+	return withSummaryJSON(path)
+}
+
+type withReporter struct {
+	r Reporter
+}
+
+func (w withReporter) apply(opt *globalOptions) {
+	opt.reporters.add(w.r)
+}
+
+// Send assertion events to the given reporter, e.g. [JUnitReporter] or
+// [GitHubReporter]. May be given multiple times.
+func WithReporter(r Reporter) InitOption {
+	return withReporter{r}
+}
+
+type withColorMode ColorMode
+
+func (m withColorMode) apply(opt *globalOptions) {
+	opt.colorMode = ColorMode(m)
+}
+
+// Set the coloring of diffs for comparers using [ColorAuto]. Can be
+// overridden using a command line flag (see [DefaultColorFlagName]).
+func WithColorMode(m ColorMode) InitOption {
+	return withColorMode(m)
+}
+
+type withColorFlagName string
+
+func (n withColorFlagName) apply(opt *globalOptions) {
+	opt.colorFlagName = string(n)
+}
+
+// Override the name of the flag for coloring diffs. An empty name disables
+// the flag.
+func WithColorFlagName(name string) InitOption {
+	return withColorFlagName(name)
+}
+
+type withDecisionsFile string
+
+func (p withDecisionsFile) apply(opt *globalOptions) {
+	opt.decisionsFile = string(p)
+}
+
+// Read decisions for interactive updates ("-update_golden_files=interactive")
+// from a file instead of prompting. Each line consists of "accept" or
+// "reject" followed by a pattern matched against golden file paths using
+// [path.Match], e.g. "accept testdata/*.json". Lines starting with "#" are
+// ignored. The first matching rule wins and updates matching no rule are
+// rejected. Can also be set using a command line flag (see
+// [DefaultDecisionsFlagName]).
+func WithDecisionsFile(path string) InitOption {
+	return withDecisionsFile(path)
+}
+
+type withDecisionsFlagName string
+
+func (n withDecisionsFlagName) apply(opt *globalOptions) {
+	opt.decisionsFlagName = string(n)
+}
+
+// Override the name of the flag for the decisions file. An empty name
+// disables the flag.
+func WithDecisionsFlagName(name string) InitOption {
+	return withDecisionsFlagName(name)
+}
+
+type withWriteRootFlagName string
+
+func (n withWriteRootFlagName) apply(opt *globalOptions) {
+	opt.writeRootFlagName = string(n)
+}
+
+// Override the name of the flag for the write root directory. An empty name
+// disables the flag.
+func WithWriteRootFlagName(name string) InitOption {
+	return withWriteRootFlagName(name)
+}
+
 // Initialize the package and register a command line flag. Must be called
 // before parsing flags. Example usage in a test file:
 //
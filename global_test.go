@@ -2,6 +2,8 @@ package aurum
 
 import (
 	"flag"
+	"io"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -51,3 +53,111 @@ func TestGlobalOptionsInit(t *testing.T) {
 		})
 	}
 }
+
+func TestGlobalOptionsUpdateFlag(t *testing.T) {
+	for _, tc := range []struct {
+		args        []string
+		wantUpdates bool
+		wantDryRun  bool
+		wantErr     bool
+	}{
+		{},
+		{
+			args:        []string{"-update_golden_files"},
+			wantUpdates: true,
+		},
+		{
+			args:        []string{"-update_golden_files=true"},
+			wantUpdates: true,
+		},
+		{
+			args: []string{"-update_golden_files=false"},
+		},
+		{
+			args:        []string{"-update_golden_files=dryrun"},
+			wantUpdates: true,
+			wantDryRun:  true,
+		},
+		{
+			args:        []string{"-update_golden_files=interactive"},
+			wantUpdates: true,
+		},
+		{
+			args:    []string{"-update_golden_files=unknown"},
+			wantErr: true,
+		},
+	} {
+		t.Run(strings.Join(tc.args, " "), func(t *testing.T) {
+			g := globalOptions{
+				flagSet:  flag.NewFlagSet("", flag.ContinueOnError),
+				flagName: DefaultUpdateFlagName,
+			}
+
+			g.flagSet.SetOutput(io.Discard)
+
+			if err := g.init(nil); err != nil {
+				t.Fatalf("init() failed: %v", err)
+			}
+
+			err := g.flagSet.Parse(tc.args)
+
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Parse() returned %v, want error %t", err, tc.wantErr)
+			}
+
+			if err == nil {
+				if diff := cmp.Diff(tc.wantUpdates, g.checkUpdatesEnabled()); diff != "" {
+					t.Errorf("Updates enabled diff (-want +got):\n%s", diff)
+				}
+
+				if diff := cmp.Diff(tc.wantDryRun, g.checkDryRunEnabled()); diff != "" {
+					t.Errorf("Dry-run enabled diff (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestGlobalOptionsWriteRoot(t *testing.T) {
+	t.Setenv(DefaultWriteRootEnv, "/from/env")
+
+	for _, tc := range []struct {
+		name string
+		opts []InitOption
+		args []string
+		want string
+	}{
+		{name: "env", want: "/from/env"},
+		{
+			name: "option",
+			opts: []InitOption{WithWriteRoot("/from/option")},
+			want: "/from/option",
+		},
+		{
+			name: "flag",
+			opts: []InitOption{WithWriteRoot("/from/option")},
+			args: []string{"-update_golden_files_root=/from/flag"},
+			want: "/from/flag",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := globalOptions{
+				flagSet:           flag.NewFlagSet("", flag.PanicOnError),
+				writeRootFlagName: DefaultWriteRootFlagName,
+				writeRootEnv:      DefaultWriteRootEnv,
+			}
+
+			if err := g.init(tc.opts); err != nil {
+				t.Fatalf("init() failed: %v", err)
+			}
+
+			if err := g.flagSet.Parse(tc.args); err != nil {
+				t.Fatalf("Parse() failed: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.want, g.checkWriteRoot()); diff != "" {
+				t.Errorf("Write root diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -1,12 +1,15 @@
 package aurum
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io/fs"
 	"net/url"
 	"os"
+	"path/filepath"
 	"reflect"
+	"unicode/utf8"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/hansmi/aurum/internal/codecutil"
@@ -26,32 +29,75 @@ type TB interface {
 // unmarshalling values.
 type Codec = codecutil.Codec
 
+// templateMarshaler is implemented by codecs whose output can be influenced by
+// the content of an existing golden file, e.g. formatting directives.
+type templateMarshaler interface {
+	marshalWithTemplate(v any, template []byte) ([]byte, error)
+}
+
 var errGoldenMissing = errors.New("golden file is missing")
 var errGoldenUnmarshalFailed = errors.New("unmarshalling golden value failed")
 var errUpdateNotSupported = errors.New("updating files is not supported")
 
+// Reason given in [UpdateSkippedEvent] when updates are disabled.
+const updatesDisabledReason = "updates are disabled"
+
 type Golden struct {
 	// Directory for storing golden files. Only used if [FS] is not set.
 	Dir string
 
+	// Resolve a relative [Dir] against the directory of the test source file
+	// calling [Golden.Assert] or [Golden.Snapshot] instead of the working
+	// directory. Non-test helper functions on the call stack are skipped. The
+	// working directory is used if source paths are unavailable, e.g. when
+	// building with "-trimpath".
+	DirRelativeToCaller bool
+
 	// Filesystem for accessing golden files. Updates are only possible if the
 	// file system implements [WriteFileFS].
 	//
-	// Defaults to [os.DirFS] for [Dir].
+	// Defaults to [os.DirFS] for [Dir]. If a write root is configured (see
+	// [WithWriteRoot]) updates to a relative [Dir] are written below the root
+	// at the directory's path relative to the module root.
 	FS fs.FS
 
-	// Codec for marshalling and unmarshalling values.
+	// Codec for marshalling and unmarshalling values. Use [ExtensionCodec] to
+	// select the codec based on the golden file name.
 	//
 	// Defaults to [JSONCodec].
 	Codec Codec
 
+	// Store the codec name, the value type and a format version in a header
+	// of the golden file. JSON values are wrapped in an envelope object, other
+	// text formats use a comment line. Binary formats are stored without
+	// header. Mismatching headers are reported as errors and in update mode
+	// cause the golden file to be rewritten.
+	Header bool
+
 	// Defaults to [Cmp].
 	Comparer Comparer
 
 	// Options for the default [Cmp] comparer.
 	CmpOptions cmp.Options
 
+	// Permit multiple tests to assert the same golden file as long as the
+	// values are identical. Asserting different values is always reported as
+	// an error.
+	AllowIdenticalReuse bool
+
+	// Receives events about assertions in addition to the reporters configured
+	// globally (see [WithReporter]).
+	Reporter Reporter
+
 	g *globalOptions
+
+	// Name of the test making assertions. Set by [Golden.Assert] and
+	// [Golden.Snapshot] for reporting.
+	test string
+
+	// Invoked with the marshalled value before comparing or writing a golden
+	// file. Set by [Golden.Assert] to detect golden files used more than once.
+	checkUse func(filename string, data []byte) error
 }
 
 func (o *Golden) applyDefaults() {
@@ -60,7 +106,11 @@ func (o *Golden) applyDefaults() {
 	}
 
 	if o.FS == nil {
-		o.FS = newWritableDirFS(o.Dir)
+		if root := o.g.checkWriteRoot(); root == "" || filepath.IsAbs(o.Dir) {
+			o.FS = newWritableDirFS(o.Dir)
+		} else {
+			o.FS = NewDirOverlayFS(o.Dir, writeRootDir(root, o.Dir))
+		}
 	}
 
 	if o.Codec == nil {
@@ -74,12 +124,70 @@ func (o *Golden) applyDefaults() {
 	}
 }
 
+// writeRootDir returns the directory below the write root for a relative
+// golden file directory. The location of the directory within its Go module
+// is retained, e.g. "pkg/testdata" for "testdata" in a package named "pkg".
+// The directory is used as-is if no module root can be found.
+func writeRootDir(root, dir string) string {
+	if abs, err := filepath.Abs(dir); err == nil {
+		if base := moduleRoot(abs); base != "" {
+			if rel, err := filepath.Rel(base, abs); err == nil && filepath.IsLocal(rel) {
+				return filepath.Join(root, rel)
+			}
+		}
+	}
+
+	return filepath.Join(root, dir)
+}
+
+// moduleRoot returns the nearest directory containing a "go.mod" file
+// starting at the given directory or an empty string if there is none.
+func moduleRoot(dir string) string {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+
+		dir = parent
+	}
+}
+
+// selectCodec determines the codec for a particular golden file.
+func (o *Golden) selectCodec(name string) {
+	if cs, ok := o.Codec.(codecSelector); ok {
+		o.Codec = cs.CodecFor(name)
+	}
+
+	if o.Header {
+		o.Codec = &headerCodec{inner: o.Codec}
+	}
+}
+
 func (o *Golden) unmarshal(data []byte, valueType reflect.Type) (any, error) {
 	return codecutil.Unmarshal(o.Codec, data, valueType)
 }
 
-func (o *Golden) verifiedMarshal(value any, valueType reflect.Type) ([]byte, error) {
-	gotBytes, err := o.Codec.Marshal(value)
+// marshalWithTemplate marshals a value using the given codec. The template is
+// only used by codecs implementing [templateMarshaler].
+func marshalWithTemplate(c Codec, value any, template []byte) ([]byte, error) {
+	if tm, ok := c.(templateMarshaler); ok {
+		return tm.marshalWithTemplate(value, template)
+	}
+
+	return c.Marshal(value)
+}
+
+func (o *Golden) marshal(value any, template []byte) ([]byte, error) {
+	return marshalWithTemplate(o.Codec, value, template)
+}
+
+func (o *Golden) verifiedMarshal(value any, valueType reflect.Type, template []byte) ([]byte, error) {
+	gotBytes, err := o.marshal(value, template)
 	if err != nil {
 		return nil, fmt.Errorf("marshalling value: %w", err)
 	}
@@ -93,8 +201,10 @@ func (o *Golden) verifiedMarshal(value any, valueType reflect.Type) ([]byte, err
 	return gotBytes, nil
 }
 
-func (o *Golden) readGolden(path string, t reflect.Type) (any, error) {
-	wantBytes, err := fs.ReadFile(o.FS, path)
+// readGolden reads the content of a golden file. The returned slice is never
+// nil on success.
+func (o *Golden) readGolden(path string) ([]byte, error) {
+	data, err := fs.ReadFile(o.FS, path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			err = multierr.Combine(errGoldenMissing, err)
@@ -102,63 +212,341 @@ func (o *Golden) readGolden(path string, t reflect.Type) (any, error) {
 			err = fmt.Errorf("reading golden file: %w", err)
 		}
 
-		return nil, err
+		data = nil
+	} else if data == nil {
+		data = []byte{}
 	}
 
-	value, err := o.unmarshal(wantBytes, t)
+	o.report(GoldenReadEvent{
+		Test: o.test,
+		Path: o.displayPath(path),
+		Size: len(data),
+		Err:  err,
+	})
+
+	return data, err
+}
+
+// unmarshalGolden unmarshals the content of a golden file. The filename is
+// only used for reporting.
+func (o *Golden) unmarshalGolden(filename string, data []byte, t reflect.Type) (any, error) {
+	value, err := o.unmarshal(data, t)
 	if err != nil {
+		o.reportUnmarshalFailed(filename, err)
+
 		err = multierr.Append(errGoldenUnmarshalFailed, err)
 	}
 
 	return value, err
 }
 
+func (o *Golden) reportUnmarshalFailed(filename string, err error) {
+	o.report(UnmarshalFailedEvent{
+		Test: o.test,
+		Path: o.displayPath(filename),
+		Err:  err,
+	})
+}
+
+// displayPath returns the path of a golden file for use in messages.
+func (o *Golden) displayPath(filename string) string {
+	if o.Dir != "" {
+		return filepath.Join(o.Dir, filename)
+	}
+
+	return filename
+}
+
+// recordUnchanged notes an unmodified golden file in dry-run mode.
+func (o *Golden) recordUnchanged(filename string, data []byte) {
+	if o.g.checkDryRunEnabled() {
+		o.g.plan.record(plannedChange{
+			path:    o.displayPath(filename),
+			kind:    changeUnchanged,
+			oldSize: len(data),
+			newSize: len(data),
+		})
+	}
+}
+
+// migrateGolden moves an unchanged golden file to its new location if
+// supported by the filesystem. Nothing is moved in dry-run mode.
+func (o *Golden) migrateGolden(filename string, logf logFunc) error {
+	m, ok := o.FS.(migratingFS)
+	if !ok || o.g.checkDryRunEnabled() {
+		return nil
+	}
+
+	if moved, err := m.migrate(filename); err != nil {
+		return fmt.Errorf("migrating golden file: %w", err)
+	} else if moved {
+		logf("Moved golden file %q to new location.", filename)
+	}
+
+	return nil
+}
+
+// writeGolden stores new content in a golden file. The previous content is
+// nil if the file didn't exist. In dry-run mode the change is only recorded.
+func (o *Golden) writeGolden(filename string, previous, data []byte, logf logFunc) error {
+	if o.g.checkDryRunEnabled() {
+		change := plannedChange{
+			path:    o.displayPath(filename),
+			kind:    changeCreated,
+			oldSize: len(previous),
+			newSize: len(data),
+		}
+
+		if previous != nil {
+			change.kind = changeModified
+		}
+
+		o.g.plan.record(change)
+		o.reportWritten(filename, previous, data, true)
+
+		logf("Would write %d bytes to golden file %q (dry-run).", len(data), filename)
+
+		return nil
+	}
+
+	if wffs, ok := o.FS.(WriteFileFS); !ok || wffs == nil {
+		return fmt.Errorf("%w: %#v", errUpdateNotSupported, o.FS)
+	} else if err := wffs.WriteFile(filename, data, 0o644); err != nil {
+		return fmt.Errorf("writing golden file: %w", err)
+	}
+
+	o.reportWritten(filename, previous, data, false)
+
+	logf("Wrote %d bytes to golden file %q.", len(data), filename)
+
+	return nil
+}
+
+func (o *Golden) reportWritten(filename string, previous, data []byte, dryRun bool) {
+	o.report(UpdateWrittenEvent{
+		Test:    o.test,
+		Path:    o.displayPath(filename),
+		OldSize: len(previous),
+		NewSize: len(data),
+		Created: previous == nil,
+		DryRun:  dryRun,
+		Codec:   o.codecName(),
+		Old:     previous,
+		New:     data,
+	})
+}
+
+// approveUpdate decides whether an update may be written in interactive
+// mode. The reason is the difference or error causing the update.
+func (o *Golden) approveUpdate(filename string, reason error, data []byte, logf logFunc) (bool, error) {
+	description := reason.Error()
+
+	if !errors.Is(reason, ErrValueDifference) {
+		if utf8.Valid(data) {
+			description += fmt.Sprintf("\nNew content (%d bytes):\n%s", len(data), data)
+		} else {
+			description += fmt.Sprintf("\nNew content: %d bytes of binary data", len(data))
+		}
+	}
+
+	return o.g.approveUpdate(o.displayPath(filename), description, logf)
+}
+
+// compareGolden compares a value with the value read from a golden file.
+// Comparers implementing [artifactComparer] may store files describing the
+// difference next to the golden file, except when updating. Artifacts are only
+// written to plain directories (see [artifactFS]) and removed once the values
+// are equal.
+func (o *Golden) compareGolden(filename string, want, got any, updatesEnabled bool) error {
+	var err error
+
+	if ac, ok := o.Comparer.(artifactComparer); !ok {
+		err = o.Comparer.Equal(want, got)
+	} else {
+		var write artifactWriter
+
+		dir := artifactFS(o.FS)
+
+		if dir != nil && !updatesEnabled {
+			write = func(suffix string, data []byte) (string, error) {
+				name := filename + suffix
+
+				return name, dir.WriteFile(name, data, 0o644)
+			}
+		}
+
+		err = ac.equalWithArtifacts(want, got, write)
+
+		if err == nil && dir != nil && !o.g.checkDryRunEnabled() {
+			err = removeArtifacts(dir, filename, ac.artifactSuffixes())
+		}
+	}
+
+	event := ComparisonEvent{
+		Test:  o.test,
+		Path:  o.displayPath(filename),
+		Equal: err == nil,
+	}
+
+	if err != nil {
+		event.Diff = err.Error()
+	}
+
+	o.report(event)
+
+	return err
+}
+
+// removeArtifacts removes files left by a previous comparison of a golden
+// file.
+func removeArtifacts(dir RemoveFS, filename string, suffixes []string) error {
+	for _, suffix := range suffixes {
+		if err := dir.Remove(filename + suffix); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("removing stale comparison artifact: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// recordResult stores the outcome of an assertion for the end-of-run summary
+// (see [Main]) and passes it to the configured reporters.
+func (o *Golden) recordResult(name string, outcome Outcome, err error) {
+	r := AssertionResult{
+		Test:    o.test,
+		Path:    o.displayPath(url.PathEscape(name)),
+		Outcome: outcome,
+	}
+
+	if err != nil {
+		r.Message = err.Error()
+	}
+
+	o.g.results.record(r)
+	o.report(r)
+}
+
+// callerRelative returns a copy of the options with [Golden.Dir] resolved
+// relative to the calling test if enabled. See [testCallerDir] for the
+// meaning of skip.
+func (o Golden) callerRelative(skip int) Golden {
+	if o.DirRelativeToCaller && o.FS == nil && !filepath.IsAbs(o.Dir) {
+		o.Dir = filepath.Join(testCallerDir(skip+1), o.Dir)
+	}
+
+	return o
+}
+
 func (o Golden) assert(name string, value any, logf logFunc) error {
+	_, err := o.assertOutcome(name, value, logf)
+
+	return err
+}
+
+// assertOutcome implements [Golden.assert] and additionally returns the
+// outcome of the assertion. Errors always have [OutcomeFailed].
+func (o Golden) assertOutcome(name string, value any, logf logFunc) (Outcome, error) {
 	o.applyDefaults()
 
 	if err := codecutil.CheckValueType(value); err != nil {
-		return err
+		return OutcomeFailed, err
 	}
 
 	value, valueType := codecutil.NormalizeValue(value)
 
-	valueBytes, err := o.verifiedMarshal(value, valueType)
+	o.selectCodec(name)
+
+	filename := url.PathEscape(name)
+
+	unlock, err := o.lockForUpdate(filename)
 	if err != nil {
-		return err
+		return OutcomeFailed, err
+	}
+
+	defer unlock()
+
+	// Read errors are only reported after marshalling the value.
+	wantBytes, readErr := o.readGolden(filename)
+
+	valueBytes, err := o.verifiedMarshal(value, valueType, wantBytes)
+	if err != nil {
+		return OutcomeFailed, err
+	}
+
+	if o.checkUse != nil {
+		if err := o.checkUse(filename, valueBytes); err != nil {
+			return OutcomeFailed, err
+		}
 	}
 
-	filename := url.PathEscape(name)
 	updatesEnabled := o.g.checkUpdatesEnabled()
 
+	var want any
 	var considerWrite bool
 	var diffErr error
 
-	if want, err := o.readGolden(filename, valueType); err == nil {
-		diffErr = o.Comparer.Equal(want, value)
+	if err = readErr; err == nil {
+		want, err = o.unmarshalGolden(filename, wantBytes, valueType)
+	}
+
+	if err == nil {
+		diffErr = o.compareGolden(filename, want, value, updatesEnabled)
 		considerWrite = diffErr != nil
-	} else if updatesEnabled && (errors.Is(err, errGoldenMissing) || errors.Is(err, errGoldenUnmarshalFailed)) {
+	} else if !(errors.Is(err, errGoldenMissing) || errors.Is(err, errGoldenUnmarshalFailed)) {
+		return OutcomeFailed, err
+	} else if updatesEnabled {
 		considerWrite = true
 	} else {
-		return err
+		o.reportSkipped(filename, updatesDisabledReason, wantBytes, valueBytes)
+
+		return OutcomeFailed, err
 	}
 
 	if updatesEnabled && considerWrite {
+		reason := err
+
 		if diffErr != nil {
 			logf("%v", diffErr)
+
+			reason = diffErr
+		}
+
+		if approved, err := o.approveUpdate(filename, reason, valueBytes, logf); err != nil {
+			return OutcomeFailed, err
+		} else if !approved {
+			o.reportSkipped(filename, updateRejectedReason, wantBytes, valueBytes)
+
+			return OutcomeFailed, multierr.Append(errUpdateRejected, reason)
+		}
+
+		if err := o.writeGolden(filename, wantBytes, valueBytes, logf); err != nil {
+			return OutcomeFailed, err
 		}
 
-		if wffs, ok := o.FS.(WriteFileFS); !ok || wffs == nil {
-			return fmt.Errorf("%w: %#v", errUpdateNotSupported, o.FS)
-		} else if err := wffs.WriteFile(filename, valueBytes, 0o644); err != nil {
-			return fmt.Errorf("writing golden file: %w", err)
+		if wantBytes == nil {
+			return OutcomeCreated, nil
 		}
 
-		logf("Wrote %d bytes to golden file %q.", len(valueBytes), filename)
+		return OutcomeUpdated, nil
 	} else if diffErr != nil {
-		return diffErr
+		o.reportSkipped(filename, updatesDisabledReason, wantBytes, valueBytes)
+
+		return OutcomeFailed, diffErr
 	}
 
-	return nil
+	if updatesEnabled {
+		if err := o.migrateGolden(filename, logf); err != nil {
+			return OutcomeFailed, err
+		}
+	}
+
+	o.recordUnchanged(filename, wantBytes)
+
+	if !bytes.Equal(wantBytes, valueBytes) {
+		return OutcomeReformatted, nil
+	}
+
+	return OutcomePassed, nil
 }
 
 // Assert checks whether the value matches the stored golden value read from
@@ -168,10 +556,47 @@ func (o Golden) assert(name string, value any, logf logFunc) error {
 // missing or differences in values are detected. The name is URL-escaped
 // before being used as a filename and should be of a reasonable length (the
 // exact limits depend on the underlying filesystem).
+//
+// Asserting the same golden file more than once within a process is reported
+// as an error unless the values are identical and either the same test is
+// repeated or [Golden.AllowIdenticalReuse] is set. The check doesn't cover
+// snapshots (see [Golden.Snapshot]).
 func (o *Golden) Assert(tb TB, name string, value any) {
 	tb.Helper()
 
-	if err := o.assert(name, value, tb.Logf); err != nil {
-		tb.Errorf("%s", err.Error())
+	opts := o.callerRelative(1)
+
+	// The location must be determined before a default filesystem is set.
+	location, ok := goldenLocation(&opts)
+
+	opts.applyDefaults()
+	opts.test = testName(tb)
+
+	if ok {
+		opts.checkUse = func(filename string, data []byte) error {
+			key := goldenKey{location, filename}
+
+			return opts.g.uses.register(key, opts.displayPath(filename), testName(tb), data, opts.AllowIdenticalReuse)
+		}
+	}
+
+	outcome, err := opts.assertOutcome(name, value, tb.Logf)
+
+	opts.recordResult(name, outcome, err)
+
+	if err != nil {
+		tb.Errorf("%s", opts.errorText(err))
+	}
+}
+
+// errorText returns the message of an error to be logged to a test. Diffs
+// reported by [Cmp] are colored if enabled.
+func (o Golden) errorText(err error) string {
+	var de *diffError
+
+	if errors.As(err, &de) && de.color.resolve(o.g.checkColorMode(), os.LookupEnv) {
+		return colorizeDiff(err.Error())
 	}
+
+	return err.Error()
 }
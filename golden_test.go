@@ -2,8 +2,12 @@ package aurum
 
 import (
 	"errors"
+	"image"
+	"image/color"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 	"syscall"
 	"testing"
 	"testing/fstest"
@@ -273,3 +277,323 @@ func TestGoldenAssertCustomStruct(t *testing.T) {
 		})
 	}
 }
+
+func TestGoldenAssertKeepsFormatDirectives(t *testing.T) {
+	o := &Golden{
+		g: &globalOptions{
+			updatesEnabled: true,
+		},
+		Dir: t.TempDir(),
+		Codec: &TextProtoCodec{
+			KeepFormatDirectives: true,
+		},
+	}
+
+	path := testutil.MustWriteFile(t, filepath.Join(o.Dir, "value"),
+		"# txtpbfmt: disable\nvalue: 1\n")
+
+	if err := o.assert("value", &wrapperspb.Int64Value{Value: 2}, t.Logf); err != nil {
+		t.Errorf("assert() failed: %v", err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "# txtpbfmt: disable\n\nvalue:"; !strings.HasPrefix(string(got), want) {
+		t.Errorf("Golden file content %q does not start with %q", got, want)
+	}
+}
+
+func TestGoldenAssertImage(t *testing.T) {
+	o := &Golden{
+		g:        &globalOptions{},
+		Dir:      t.TempDir(),
+		Codec:    &ImageCodec{},
+		Comparer: &ImageComparer{},
+	}
+
+	data, err := o.Codec.Marshal(newTestImage(4, 4))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutil.MustWriteFile(t, filepath.Join(o.Dir, "image.png"), string(data))
+
+	if err := o.assert("image.png", newTestImage(4, 4), t.Logf); err != nil {
+		t.Errorf("assert() failed: %v", err)
+	}
+
+	testutil.MustNotExist(t, filepath.Join(o.Dir, "image.png.diff.png"))
+
+	if err := o.assert("image.png", newTestImage(4, 4).SubImage(image.Rect(0, 0, 4, 4)), t.Logf); err != nil {
+		t.Errorf("assert() failed: %v", err)
+	}
+
+	changed := newTestImage(4, 4)
+	changed.Set(1, 2, color.White)
+
+	if err := o.assert("image.png", changed, t.Logf); !errors.Is(err, ErrValueDifference) {
+		t.Errorf("assert() returned %v, want %v", err, ErrValueDifference)
+	}
+
+	testutil.MustLstat(t, filepath.Join(o.Dir, "image.png.diff.png"))
+
+	// Dry runs don't modify files.
+	o.g = &globalOptions{updatesEnabled: true, dryRun: true}
+
+	if err := o.assert("image.png", newTestImage(4, 4), t.Logf); err != nil {
+		t.Errorf("assert() failed: %v", err)
+	}
+
+	testutil.MustLstat(t, filepath.Join(o.Dir, "image.png.diff.png"))
+
+	// Stale difference images are removed.
+	o.g = &globalOptions{}
+
+	if err := o.assert("image.png", newTestImage(4, 4), t.Logf); err != nil {
+		t.Errorf("assert() failed: %v", err)
+	}
+
+	testutil.MustNotExist(t, filepath.Join(o.Dir, "image.png.diff.png"))
+}
+
+func TestGoldenAssertWriteRoot(t *testing.T) {
+	root := t.TempDir()
+
+	testutil.MustMkdir(t, filepath.Join(root, "overlay"))
+
+	o := &Golden{
+		g: &globalOptions{
+			updatesEnabled: true,
+			writeRoot:      root,
+		},
+		Dir:   "overlay",
+		Codec: &TextCodec{},
+	}
+
+	if err := o.assert("value", "content", t.Logf); err != nil {
+		t.Errorf("assert() failed: %v", err)
+	}
+
+	testutil.MustLstat(t, filepath.Join(root, "overlay", "value"))
+	testutil.MustNotExist(t, "overlay")
+}
+
+func TestGoldenAssertWriteRootPackages(t *testing.T) {
+	module := t.TempDir()
+	root := t.TempDir()
+
+	testutil.MustWriteFile(t, filepath.Join(module, "go.mod"), "module example.com/test\n")
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd() failed: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if err := os.Chdir(wd); err != nil {
+			t.Errorf("Chdir() failed: %v", err)
+		}
+	})
+
+	for _, pkg := range []string{"first", filepath.Join("second", "nested")} {
+		if err := os.MkdirAll(filepath.Join(module, pkg, "testdata"), 0o755); err != nil {
+			t.Fatalf("MkdirAll() failed: %v", err)
+		}
+
+		if err := os.MkdirAll(filepath.Join(root, pkg, "testdata"), 0o755); err != nil {
+			t.Fatalf("MkdirAll() failed: %v", err)
+		}
+
+		if err := os.Chdir(filepath.Join(module, pkg)); err != nil {
+			t.Fatalf("Chdir() failed: %v", err)
+		}
+
+		o := &Golden{
+			g: &globalOptions{
+				updatesEnabled: true,
+				writeRoot:      root,
+			},
+			Dir:   "testdata",
+			Codec: &TextCodec{},
+		}
+
+		if err := o.assert("value", pkg, t.Logf); err != nil {
+			t.Errorf("assert() failed: %v", err)
+		}
+
+		testutil.MustNotExist(t, filepath.Join(module, pkg, "testdata", "value"))
+	}
+
+	for _, pkg := range []string{"first", filepath.Join("second", "nested")} {
+		path := filepath.Join(root, pkg, "testdata", "value")
+
+		if got, err := os.ReadFile(path); err != nil {
+			t.Errorf("ReadFile() failed: %v", err)
+		} else if diff := cmp.Diff(pkg, string(got)); diff != "" {
+			t.Errorf("Golden file %q diff (-want +got):\n%s", path, diff)
+		}
+	}
+}
+
+func TestGoldenAssertTruncatedDiff(t *testing.T) {
+	const content = "[\"a\", \"b\", \"c\"]\n"
+
+	for _, tc := range []struct {
+		name string
+
+		// Returns the filesystem and the list of files after the assertion.
+		setup func(t *testing.T) (fs.FS, func() []string)
+
+		wantArtifact bool
+	}{
+		{
+			name: "directory",
+			setup: func(t *testing.T) (fs.FS, func() []string) {
+				dir := t.TempDir()
+
+				testutil.MustWriteFile(t, filepath.Join(dir, "value"), content)
+
+				return newWritableDirFS(dir), func() []string {
+					names, _ := fs.Glob(os.DirFS(dir), "*")
+					return names
+				}
+			},
+			wantArtifact: true,
+		},
+		{
+			name: "memfs",
+			setup: func(t *testing.T) (fs.FS, func() []string) {
+				m := NewMemFS(map[string][]byte{"value": []byte(content)})
+
+				return m, func() []string {
+					names, _ := fs.Glob(m, "*")
+					return names
+				}
+			},
+		},
+		{
+			name: "txtar",
+			setup: func(t *testing.T) (fs.FS, func() []string) {
+				f := NewTxtarFS(filepath.Join(t.TempDir(), "archive.txtar"))
+
+				if err := f.WriteFile("value", []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+
+				return f, func() []string {
+					names, _ := fs.Glob(f, "*")
+					return names
+				}
+			},
+		},
+		{
+			name: "legacy",
+			setup: func(t *testing.T) (fs.FS, func() []string) {
+				dir := t.TempDir()
+
+				testutil.MustWriteFile(t, filepath.Join(dir, "value.golden"), content)
+
+				return NewLegacyFS(t, dir, GoldieLayout), func() []string {
+					names, _ := fs.Glob(os.DirFS(dir), "*")
+					return names
+				}
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fsys, list := tc.setup(t)
+			before := list()
+
+			o := &Golden{
+				g:  &globalOptions{},
+				FS: fsys,
+				Comparer: &Cmp{
+					MaxDiffLines: 2,
+					Color:        ColorNever,
+				},
+			}
+
+			err := o.assert("value", []string{"x", "y", "z"}, t.Logf)
+
+			if !errors.Is(err, ErrValueDifference) {
+				t.Errorf("assert() returned %v, want %v", err, ErrValueDifference)
+			} else if got := strings.Contains(err.Error(), `full diff written to "value.diff.txt"`); got != tc.wantArtifact {
+				t.Errorf("assert() returned unexpected error: %v", err)
+			}
+
+			want := before
+
+			if tc.wantArtifact {
+				want = append(want, "value.diff.txt")
+			}
+
+			if diff := cmp.Diff(want, list()); diff != "" {
+				t.Errorf("Files diff (-want +got):\n%s", diff)
+			}
+
+			// The full diff is removed once the values are equal.
+			if err := o.assert("value", []string{"a", "b", "c"}, t.Logf); err != nil {
+				t.Errorf("assert() failed: %v", err)
+			}
+
+			if diff := cmp.Diff(before, list()); diff != "" {
+				t.Errorf("Files diff after passing (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGoldenAssertColor(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		color  ColorMode
+		global ColorMode
+		want   bool
+	}{
+		{name: "comparer", color: ColorAlways, global: ColorNever, want: true},
+		{name: "global", global: ColorAlways, want: true},
+		{name: "disabled", color: ColorNever, global: ColorAlways},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &fakeReporter{}
+			tb := &fakeTB{name: "TestColor"}
+
+			o := &Golden{
+				g: &globalOptions{colorMode: tc.global},
+				FS: NewMemFS(map[string][]byte{
+					"file": []byte("\"value\"\n"),
+				}),
+				Comparer: &Cmp{Color: tc.color},
+				Reporter: r,
+			}
+
+			o.Assert(tb, "file", "changed")
+
+			if len(tb.errors) != 1 {
+				t.Fatalf("Assert() reported %d errors, want 1", len(tb.errors))
+			}
+
+			if got := strings.Contains(tb.errors[0], ansiRed); got != tc.want {
+				t.Errorf("Test error colored %t, want %t:\n%q", got, tc.want, tb.errors[0])
+			}
+
+			for _, e := range r.events {
+				var text string
+
+				switch e := e.(type) {
+				case ComparisonEvent:
+					text = e.Diff
+				case AssertionResult:
+					text = e.Message
+				}
+
+				if strings.Contains(text, "\x1b[") {
+					t.Errorf("Event contains escape sequences: %#v", e)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+package aurum
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/hansmi/aurum/internal/codecutil"
+)
+
+// Version of the golden file header format.
+const headerFormatVersion = 1
+
+var errGoldenHeaderMismatch = errors.New("golden file header mismatch")
+
+type goldenHeader struct {
+	Codec   string `json:"codec"`
+	Type    string `json:"type"`
+	Version int    `json:"version"`
+}
+
+func (h goldenHeader) check(want goldenHeader) error {
+	switch {
+	case h.Version != want.Version:
+		return fmt.Errorf("%w: format version %d, want %d", errGoldenHeaderMismatch, h.Version, want.Version)
+	case h.Codec != want.Codec:
+		return fmt.Errorf("%w: written by codec %q, want %q", errGoldenHeaderMismatch, h.Codec, want.Codec)
+	case h.Type != want.Type:
+		return fmt.Errorf("%w: contains value of type %q, want %q", errGoldenHeaderMismatch, h.Type, want.Type)
+	}
+
+	return nil
+}
+
+// CodecName returns a short and stable name for a codec, e.g. "json" or
+// "textproto". Wrapping codecs include the name of their inner codec, e.g.
+// "gzip/text". The type name is returned for unknown codecs.
+func CodecName(c Codec) string {
+	switch c := c.(type) {
+	case *JSONCodec:
+		return "json"
+	case *TextCodec, TextCodec:
+		return "text"
+	case *TextProtoCodec:
+		return "textproto"
+	case *XMLCodec:
+		return "xml"
+	case *CSVCodec:
+		return "csv"
+	case *HexdumpCodec, HexdumpCodec:
+		return "hexdump"
+	case *ImageCodec:
+		return "png"
+	case *Base64Codec:
+		return "base64/" + CodecName(innerCodec(c.Inner))
+	case *GzipCodec:
+		return "gzip/" + CodecName(innerCodec(c.Inner))
+	}
+
+	return strings.TrimPrefix(fmt.Sprintf("%T", c), "*")
+}
+
+// typeName returns the name of the value type. The argument must be
+// a pointer. The full name is used for protocol buffer messages.
+func typeName(v any) (string, error) {
+	rv, m, err := codecutil.PrepareMarshalValue(v)
+	if err != nil {
+		return "", err
+	}
+
+	if m != nil {
+		return string(m.ProtoReflect().Descriptor().FullName()), nil
+	}
+
+	t := rv.Type()
+
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t.String(), nil
+}
+
+// headerCodec adds a header describing the codec and value type to the
+// output of an inner codec. JSON is wrapped in an envelope object, other
+// formats use a leading comment line, placed after an XML declaration.
+type headerCodec struct {
+	inner Codec
+}
+
+var _ Codec = (*headerCodec)(nil)
+var _ templateMarshaler = (*headerCodec)(nil)
+
+type jsonHeaderEnvelope struct {
+	Header *goldenHeader   `json:"aurum"`
+	Value  json.RawMessage `json:"value"`
+}
+
+// supported returns whether the inner codec produces text data.
+func (c *headerCodec) supported() bool {
+	switch c.inner.(type) {
+	case *ImageCodec, *GzipCodec:
+		return false
+	}
+
+	return true
+}
+
+func (c *headerCodec) commentDelimiters() (string, string) {
+	if _, ok := c.inner.(*XMLCodec); ok {
+		return "<!-- aurum: ", " -->"
+	}
+
+	return "# aurum: ", ""
+}
+
+// preamble returns the length of data which must precede the header comment.
+// For XML that's the declaration including the line break, if any.
+func (c *headerCodec) preamble(data []byte) int {
+	if _, ok := c.inner.(*XMLCodec); !ok || !bytes.HasPrefix(data, []byte("<?xml")) {
+		return 0
+	}
+
+	end := bytes.Index(data, []byte("?>"))
+	if end < 0 {
+		return 0
+	}
+
+	end += len("?>")
+
+	if bytes.HasPrefix(data[end:], []byte{'\n'}) {
+		end++
+	}
+
+	return end
+}
+
+func (c *headerCodec) header(v any) (goldenHeader, error) {
+	name, err := typeName(v)
+
+	return goldenHeader{
+		Codec:   CodecName(c.inner),
+		Type:    name,
+		Version: headerFormatVersion,
+	}, err
+}
+
+// split separates the header from the inner data.
+func (c *headerCodec) split(data []byte) (*goldenHeader, []byte, error) {
+	if _, ok := c.inner.(*JSONCodec); ok {
+		var envelope jsonHeaderEnvelope
+
+		if err := json.Unmarshal(data, &envelope); err != nil || envelope.Header == nil {
+			return nil, data, nil
+		}
+
+		return envelope.Header, envelope.Value, nil
+	}
+
+	prefix, suffix := c.commentDelimiters()
+
+	head := data[:c.preamble(data)]
+
+	line, rest, _ := bytes.Cut(data[len(head):], []byte{'\n'})
+
+	if !bytes.HasPrefix(line, []byte(prefix)) {
+		return nil, data, nil
+	}
+
+	line = bytes.TrimSuffix(bytes.TrimPrefix(line, []byte(prefix)), []byte(suffix))
+
+	var h goldenHeader
+
+	if err := json.Unmarshal(line, &h); err != nil {
+		return nil, nil, fmt.Errorf("%w: parsing header: %v", errGoldenHeaderMismatch, err)
+	}
+
+	if len(head) > 0 {
+		rest = append(bytes.Clone(head), rest...)
+	}
+
+	return &h, rest, nil
+}
+
+func (c *headerCodec) marshalWithTemplate(v any, template []byte) ([]byte, error) {
+	if template != nil {
+		if _, rest, err := c.split(template); err == nil {
+			template = rest
+		}
+	}
+
+	data, err := marshalWithTemplate(c.inner, v, template)
+	if err != nil || !c.supported() {
+		return data, err
+	}
+
+	h, err := c.header(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+
+	if _, ok := c.inner.(*JSONCodec); ok {
+		envelope, err := json.Marshal(jsonHeaderEnvelope{
+			Header: &h,
+			Value:  json.RawMessage(data),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if err := json.Indent(&buf, envelope, "", "  "); err != nil {
+			return nil, err
+		}
+
+		buf.WriteByte('\n')
+	} else {
+		encoded, err := json.Marshal(h)
+		if err != nil {
+			return nil, err
+		}
+
+		prefix, suffix := c.commentDelimiters()
+
+		// The XML declaration must come first.
+		head := data[:c.preamble(data)]
+		data = data[len(head):]
+
+		buf.Write(head)
+
+		if len(head) > 0 && !bytes.HasSuffix(head, []byte{'\n'}) {
+			buf.WriteByte('\n')
+		}
+
+		buf.WriteString(prefix)
+		buf.Write(encoded)
+		buf.WriteString(suffix)
+		buf.WriteByte('\n')
+		buf.Write(data)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (c *headerCodec) Marshal(v any) ([]byte, error) {
+	return c.marshalWithTemplate(v, nil)
+}
+
+func (c *headerCodec) Unmarshal(data []byte, v any) error {
+	if !c.supported() {
+		return c.inner.Unmarshal(data, v)
+	}
+
+	h, rest, err := c.split(data)
+	if err != nil {
+		return err
+	}
+
+	if h == nil {
+		return fmt.Errorf("%w: header is missing", errGoldenHeaderMismatch)
+	}
+
+	// The destination is a pointer to the value pointer.
+	want, err := c.header(reflect.ValueOf(v).Elem().Interface())
+	if err != nil {
+		return err
+	}
+
+	if err := h.check(want); err != nil {
+		return err
+	}
+
+	return c.inner.Unmarshal(rest, v)
+}
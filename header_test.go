@@ -0,0 +1,246 @@
+package aurum
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/hansmi/aurum/internal/codectest"
+	"github.com/hansmi/aurum/internal/codecutil"
+	"github.com/hansmi/aurum/internal/testutil"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestHeaderCodec(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		inner Codec
+		tests []codectest.Case
+	}{
+		{
+			name:  "json",
+			inner: &JSONCodec{},
+			tests: []codectest.Case{
+				{Name: "string", Value: "hello"},
+				{Name: "int slice", Value: []int{1, 2, 3}},
+				{Name: "proto", Value: &wrapperspb.Int64Value{Value: 4321}},
+			},
+		},
+		{
+			name:  "text",
+			inner: &TextCodec{},
+			tests: []codectest.Case{
+				{Name: "string", Value: "# aurum: not a header\n"},
+				{Name: "bytes", Value: []byte("data")},
+			},
+		},
+		{
+			name:  "textproto",
+			inner: &TextProtoCodec{SchemaComments: true},
+			tests: []codectest.Case{
+				{Name: "proto", Value: &wrapperspb.StringValue{Value: "text"}},
+			},
+		},
+		{
+			name:  "xml",
+			inner: &XMLCodec{},
+			tests: []codectest.Case{
+				{Name: "string", Value: "text"},
+			},
+		},
+		{
+			name:  "xml declaration",
+			inner: &XMLCodec{Declaration: true},
+			tests: []codectest.Case{
+				{Name: "string", Value: "text"},
+			},
+		},
+		{
+			name:  "gzip",
+			inner: &GzipCodec{},
+			tests: []codectest.Case{
+				{Name: "string", Value: "text"},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			codectest.AssertAll(t, &headerCodec{inner: tc.inner}, tc.tests)
+		})
+	}
+}
+
+func TestHeaderCodecMarshal(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		inner Codec
+		value any
+		want  string
+	}{
+		{
+			name:  "json",
+			inner: &JSONCodec{},
+			value: []string{"a"},
+			want: `{
+  "aurum": {
+    "codec": "json",
+    "type": "[]string",
+    "version": 1
+  },
+  "value": [
+    "a"
+  ]
+}
+`,
+		},
+		{
+			name:  "textproto",
+			inner: &TextProtoCodec{},
+			value: &wrapperspb.Int64Value{Value: 1},
+			want:  "# aurum: {\"codec\":\"textproto\",\"type\":\"google.protobuf.Int64Value\",\"version\":1}\nvalue: 1\n",
+		},
+		{
+			name:  "xml",
+			inner: &XMLCodec{},
+			value: 1,
+			want:  "<!-- aurum: {\"codec\":\"xml\",\"type\":\"int\",\"version\":1} -->\n<int>1</int>\n",
+		},
+		{
+			name:  "xml declaration",
+			inner: &XMLCodec{Declaration: true},
+			value: 1,
+			want: "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n" +
+				"<!-- aurum: {\"codec\":\"xml\",\"type\":\"int\",\"version\":1} -->\n" +
+				"<int>1</int>\n",
+		},
+		{
+			name:  "wrapped",
+			inner: &Base64Codec{Inner: &TextCodec{}},
+			value: "text",
+			want:  "# aurum: {\"codec\":\"base64/text\",\"type\":\"string\",\"version\":1}\ndGV4dA==\n",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			value, _ := codecutil.NormalizeValue(tc.value)
+
+			got, err := (&headerCodec{inner: tc.inner}).Marshal(value)
+			if err != nil {
+				t.Fatalf("Marshal() failed: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.want, string(got)); diff != "" {
+				t.Errorf("Marshal() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestHeaderCodecUnmarshal(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		inner   Codec
+		input   string
+		wantErr error
+	}{
+		{
+			name:  "json",
+			inner: &JSONCodec{},
+			input: `{"aurum": {"codec": "json", "type": "string", "version": 1}, "value": "x"}`,
+		},
+		{
+			name:    "json without header",
+			inner:   &JSONCodec{},
+			input:   `"x"`,
+			wantErr: errGoldenHeaderMismatch,
+		},
+		{
+			name:    "json type mismatch",
+			inner:   &JSONCodec{},
+			input:   `{"aurum": {"codec": "json", "type": "int", "version": 1}, "value": 1}`,
+			wantErr: errGoldenHeaderMismatch,
+		},
+		{
+			name:  "text",
+			inner: &TextCodec{},
+			input: "# aurum: {\"codec\":\"text\",\"type\":\"string\",\"version\":1}\nx",
+		},
+		{
+			name:    "text without header",
+			inner:   &TextCodec{},
+			input:   "x",
+			wantErr: errGoldenHeaderMismatch,
+		},
+		{
+			name:    "text codec mismatch",
+			inner:   &TextCodec{},
+			input:   "# aurum: {\"codec\":\"hexdump\",\"type\":\"string\",\"version\":1}\n",
+			wantErr: errGoldenHeaderMismatch,
+		},
+		{
+			name:    "text version mismatch",
+			inner:   &TextCodec{},
+			input:   "# aurum: {\"codec\":\"text\",\"type\":\"string\",\"version\":1000}\nx",
+			wantErr: errGoldenHeaderMismatch,
+		},
+		{
+			name:  "xml declaration",
+			inner: &XMLCodec{},
+			input: "<?xml version=\"1.0\"?>\n<!-- aurum: {\"codec\":\"xml\",\"type\":\"string\",\"version\":1} -->\n<string>x</string>\n",
+		},
+		{
+			name:    "text bad header",
+			inner:   &TextCodec{},
+			input:   "# aurum: {\nx",
+			wantErr: errGoldenHeaderMismatch,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, valueType := codecutil.NormalizeValue("")
+
+			got, err := codecutil.Unmarshal(&headerCodec{inner: tc.inner}, []byte(tc.input), valueType)
+
+			if diff := cmp.Diff(tc.wantErr, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("Error diff (-want +got):\n%s", diff)
+			}
+
+			if err == nil {
+				if diff := cmp.Diff("x", *(got.(*string))); diff != "" {
+					t.Errorf("Value diff (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestGoldenAssertHeader(t *testing.T) {
+	for _, updatesEnabled := range []bool{false, true} {
+		o := &Golden{
+			g: &globalOptions{
+				updatesEnabled: updatesEnabled,
+			},
+			Dir:    t.TempDir(),
+			Header: true,
+		}
+
+		path := testutil.MustWriteFile(t, filepath.Join(o.Dir, "value"),
+			`{"aurum": {"codec": "json", "type": "aurum.oldName", "version": 1}, "value": 1}`)
+
+		err := o.assert("value", 1, t.Logf)
+
+		if updatesEnabled {
+			if err != nil {
+				t.Errorf("assert() failed: %v", err)
+			}
+
+			if content, err := os.ReadFile(path); err != nil {
+				t.Error(err)
+			} else if !strings.Contains(string(content), `"type": "int"`) {
+				t.Errorf("Golden file was not rewritten:\n%s", content)
+			}
+		} else if diff := cmp.Diff(errGoldenHeaderMismatch, err, cmpopts.EquateErrors()); diff != "" {
+			t.Errorf("Error diff (-want +got):\n%s", diff)
+		}
+	}
+}
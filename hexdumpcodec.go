@@ -0,0 +1,166 @@
+package aurum
+
+import (
+	"bufio"
+	"bytes"
+	"encoding"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/hansmi/aurum/internal/codecutil"
+)
+
+// HexdumpCodec stores binary data in the canonical hex+ASCII format also
+// produced by "hexdump -C". Supports byte slices, strings and values
+// implementing [encoding.BinaryMarshaler] and [encoding.BinaryUnmarshaler].
+//
+// Each line contains up to 16 bytes prefixed by their offset. The last line
+// contains the total length. Lines consisting of a single "*" are accepted
+// when reading and repeat the previous line until the next offset.
+type HexdumpCodec struct{}
+
+var _ Codec = (*HexdumpCodec)(nil)
+
+func (HexdumpCodec) Marshal(v any) ([]byte, error) {
+	rv, _, err := codecutil.PrepareMarshalValue(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+
+	if bm, ok := rv.Interface().(encoding.BinaryMarshaler); ok {
+		if data, err = bm.MarshalBinary(); err != nil {
+			return nil, err
+		}
+	} else {
+		switch v := reflect.Indirect(rv).Interface().(type) {
+		case []byte:
+			data = v
+		case string:
+			data = []byte(v)
+		default:
+			return nil, fmt.Errorf("%w: marshalling %T as hexdump is not supported", os.ErrInvalid, v)
+		}
+	}
+
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var buf bytes.Buffer
+
+	buf.WriteString(hex.Dump(data))
+	fmt.Fprintf(&buf, "%08x\n", len(data))
+
+	return buf.Bytes(), nil
+}
+
+func parseHexdumpLine(line string) (int, []byte, error) {
+	offsetText, rest, _ := strings.Cut(line, " ")
+
+	offset, err := strconv.ParseUint(offsetText, 16, 0)
+	if err != nil {
+		return 0, nil, fmt.Errorf("invalid offset: %w", err)
+	}
+
+	// Strip ASCII column
+	rest, _, _ = strings.Cut(rest, "|")
+
+	var data []byte
+
+	for _, field := range strings.Fields(rest) {
+		b, err := hex.DecodeString(field)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		data = append(data, b...)
+	}
+
+	return int(offset), data, nil
+}
+
+func decodeHexdump(input []byte) ([]byte, error) {
+	var result []byte
+	var previous []byte
+	var repeat bool
+
+	scanner := bufio.NewScanner(bytes.NewReader(input))
+
+	for lineno := 1; scanner.Scan(); lineno++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		if line == "" {
+			continue
+		}
+
+		if line == "*" {
+			repeat = true
+			continue
+		}
+
+		offset, data, err := parseHexdumpLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineno, err)
+		}
+
+		if repeat {
+			for len(previous) > 0 && len(result)+len(previous) <= offset {
+				result = append(result, previous...)
+			}
+
+			repeat = false
+		}
+
+		if offset != len(result) {
+			return nil, fmt.Errorf("line %d: offset 0x%x, want 0x%x", lineno, offset, len(result))
+		}
+
+		result = append(result, data...)
+		previous = data
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (HexdumpCodec) Unmarshal(input []byte, v any) error {
+	rv, _, err := codecutil.PrepareUnmarshalDest(v)
+	if err != nil {
+		return err
+	}
+
+	data, err := decodeHexdump(input)
+	if err != nil {
+		return fmt.Errorf("%w: parsing hexdump: %v", os.ErrInvalid, err)
+	}
+
+	if rv.Elem().Kind() == reflect.Pointer {
+		if rv.Elem().IsNil() {
+			rv.Elem().Set(reflect.New(rv.Type().Elem().Elem()))
+		}
+
+		rv = rv.Elem()
+	}
+
+	switch v := rv.Interface().(type) {
+	case *[]byte:
+		*v = data
+		return nil
+	case *string:
+		*v = string(data)
+		return nil
+	case encoding.BinaryUnmarshaler:
+		return v.UnmarshalBinary(data)
+	}
+
+	return fmt.Errorf("%w: unmarshalling hexdump into %T is not supported", os.ErrInvalid, v)
+}
@@ -0,0 +1,113 @@
+package aurum
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/hansmi/aurum/internal/codectest"
+	"github.com/hansmi/aurum/internal/ref"
+)
+
+func TestHexdumpCodec(t *testing.T) {
+	codectest.AssertAll(t, &HexdumpCodec{}, []codectest.Case{
+		{
+			Name:  "empty",
+			Value: []byte{},
+		},
+		{
+			Name:  "byte slice",
+			Value: []byte("Hello, World!\n\x00\x01\x02\xff"),
+		},
+		{
+			Name:  "byte slice pointer",
+			Value: ref.Ref([]byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}),
+		},
+		{
+			Name:  "string",
+			Value: "text with | pipe",
+		},
+		{
+			Name:  "binary marshaler",
+			Value: time.Date(2000, time.January, 1, 0, 1, 2, 3, time.UTC),
+		},
+		{
+			Name:           "int",
+			Value:          1,
+			WantMarshalErr: os.ErrInvalid,
+		},
+	})
+}
+
+func TestHexdumpCodecMarshal(t *testing.T) {
+	got, err := HexdumpCodec{}.Marshal([]byte("Hello, World!\n"))
+	if err != nil {
+		t.Fatalf("Marshal() failed: %v", err)
+	}
+
+	want := "" +
+		"00000000  48 65 6c 6c 6f 2c 20 57  6f 72 6c 64 21 0a        |Hello, World!.|\n" +
+		"0000000e\n"
+
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("Marshal() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestHexdumpCodecUnmarshal(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		input   string
+		want    []byte
+		wantErr error
+	}{
+		{name: "empty"},
+		{
+			name:  "without ASCII column",
+			input: "00000000  61 62\n00000002  63\n",
+			want:  []byte("abc"),
+		},
+		{
+			name: "repeated lines",
+			input: "" +
+				"00000000  00 00 00 00 00 00 00 00  00 00 00 00 00 00 00 00  |................|\n" +
+				"*\n" +
+				"00000030  01                                                |.|\n" +
+				"00000031\n",
+			want: append(make([]byte, 0x30), 1),
+		},
+		{
+			name:    "offset mismatch",
+			input:   "00000000  61 62\n00000003  63\n",
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name:    "invalid hex",
+			input:   "00000000  6x\n",
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name:    "invalid offset",
+			input:   "zzzz  61\n",
+			wantErr: os.ErrInvalid,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []byte
+
+			err := HexdumpCodec{}.Unmarshal([]byte(tc.input), &got)
+
+			if diff := cmp.Diff(tc.wantErr, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("Error diff (-want +got):\n%s", diff)
+			}
+
+			if err == nil {
+				if diff := cmp.Diff(tc.want, got, cmpopts.EquateEmpty()); diff != "" {
+					t.Errorf("Value diff (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package aurum
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// highlightToken is a piece of text with a CSS class for syntax highlighting.
+// Plain text has an empty class.
+type highlightToken struct {
+	Class string
+	Text  string
+}
+
+type highlighter struct {
+	src    string
+	pos    int
+	tokens []highlightToken
+}
+
+func (h *highlighter) emit(class string, end int) {
+	text := h.src[h.pos:end]
+	h.pos = end
+
+	if n := len(h.tokens); n > 0 && h.tokens[n-1].Class == class {
+		h.tokens[n-1].Text += text
+		return
+	}
+
+	h.tokens = append(h.tokens, highlightToken{Class: class, Text: text})
+}
+
+// advance emits the rune at the start of an iteration as plain text if no
+// token was emitted, ensuring progress on unexpected input.
+func (h *highlighter) advance(start, size int) {
+	if h.pos == start {
+		h.emit("", start+size)
+	}
+}
+
+// scanWhile returns the end of the run of runes matching a predicate.
+func (h *highlighter) scanWhile(start int, fn func(rune) bool) int {
+	end := start
+
+	for end < len(h.src) {
+		r, size := utf8.DecodeRuneInString(h.src[end:])
+		if !fn(r) {
+			break
+		}
+
+		end += size
+	}
+
+	return end
+}
+
+// scanString returns the end of a quoted string starting at the current
+// position. Backslash escapes are skipped.
+func (h *highlighter) scanString() int {
+	quote := h.src[h.pos]
+
+	for end := h.pos + 1; end < len(h.src); end++ {
+		switch h.src[end] {
+		case '\\':
+			end++
+		case quote:
+			return end + 1
+		case '\n':
+			return end
+		}
+	}
+
+	return len(h.src)
+}
+
+// nextSignificant returns the next byte after whitespace following the given
+// offset, or zero.
+func (h *highlighter) nextSignificant(offset int) byte {
+	if end := h.scanWhile(offset, unicode.IsSpace); end < len(h.src) {
+		return h.src[end]
+	}
+
+	return 0
+}
+
+func isNumberRune(r rune) bool {
+	return r == '-' || r == '+' || r == '.' || r == 'e' || r == 'E' || r == 'x' || r == 'X' ||
+		unicode.IsDigit(r) || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F')
+}
+
+func isIdentRune(r rune) bool {
+	return r == '_' || r == '.' || r == '/' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// highlight splits content into tokens for syntax highlighting based on the
+// name of a codec. Content of codecs without highlighting support is
+// returned as a single plain token.
+func highlight(codec, content string) []highlightToken {
+	h := &highlighter{src: content}
+
+	switch codec {
+	case "json":
+		h.highlightJSON()
+	case "textproto":
+		h.highlightTextProto()
+	default:
+		h.emit("", len(content))
+	}
+
+	return h.tokens
+}
+
+func (h *highlighter) highlightJSON() {
+	for h.pos < len(h.src) {
+		start := h.pos
+		r, size := utf8.DecodeRuneInString(h.src[h.pos:])
+
+		switch {
+		case r == '"':
+			end := h.scanString()
+
+			if h.nextSignificant(end) == ':' {
+				h.emit("key", end)
+			} else {
+				h.emit("string", end)
+			}
+
+		case r == '-' || (r >= '0' && r <= '9'):
+			h.emit("number", h.scanWhile(h.pos, isNumberRune))
+
+		case r >= 'a' && r <= 'z':
+			h.emit("literal", h.scanWhile(h.pos, unicode.IsLetter))
+
+		default:
+			h.emit("", h.pos+size)
+		}
+
+		h.advance(start, size)
+	}
+}
+
+func (h *highlighter) highlightTextProto() {
+	for h.pos < len(h.src) {
+		start := h.pos
+		r, size := utf8.DecodeRuneInString(h.src[h.pos:])
+
+		switch {
+		case r == '#':
+			end := strings.IndexByte(h.src[h.pos:], '\n')
+			if end < 0 {
+				end = len(h.src)
+			} else {
+				end += h.pos
+			}
+
+			h.emit("comment", end)
+
+		case r == '"' || r == '\'':
+			h.emit("string", h.scanString())
+
+		case r == '-' || (r >= '0' && r <= '9'):
+			h.emit("number", h.scanWhile(h.pos, isNumberRune))
+
+		case r == '[' && h.scanWhile(h.pos+1, isIdentRune) > h.pos+1:
+			// Extension or Any type URL.
+			end := strings.IndexByte(h.src[h.pos:], ']')
+			if end < 0 {
+				end = len(h.src)
+			} else {
+				end += h.pos + 1
+			}
+
+			h.emit("key", end)
+
+		case r == '_' || unicode.IsLetter(r):
+			end := h.scanWhile(h.pos, isIdentRune)
+
+			switch h.nextSignificant(end) {
+			case ':', '{', '<':
+				h.emit("key", end)
+			default:
+				h.emit("literal", end)
+			}
+
+		default:
+			h.emit("", h.pos+size)
+		}
+
+		h.advance(start, size)
+	}
+}
@@ -0,0 +1,129 @@
+package aurum
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestHighlight(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		codec   string
+		content string
+		want    []highlightToken
+	}{
+		{
+			name:    "text",
+			codec:   "text",
+			content: "a: \"b\"\n",
+			want: []highlightToken{
+				{Text: "a: \"b\"\n"},
+			},
+		},
+		{
+			name:    "json",
+			codec:   "json",
+			content: `{"key": "va\"lue", "n": -1.5e3, "ok": [true, null]}`,
+			want: []highlightToken{
+				{Text: "{"},
+				{Class: "key", Text: `"key"`},
+				{Text: ": "},
+				{Class: "string", Text: `"va\"lue"`},
+				{Text: ", "},
+				{Class: "key", Text: `"n"`},
+				{Text: ": "},
+				{Class: "number", Text: "-1.5e3"},
+				{Text: ", "},
+				{Class: "key", Text: `"ok"`},
+				{Text: ": ["},
+				{Class: "literal", Text: "true"},
+				{Text: ", "},
+				{Class: "literal", Text: "null"},
+				{Text: "]}"},
+			},
+		},
+		{
+			name:    "textproto",
+			codec:   "textproto",
+			content: "# comment\nname: \"x\"\nkind: ENUM_VALUE\nsub {\n  [ext.field]: 12\n}\n",
+			want: []highlightToken{
+				{Class: "comment", Text: "# comment"},
+				{Text: "\n"},
+				{Class: "key", Text: "name"},
+				{Text: ": "},
+				{Class: "string", Text: `"x"`},
+				{Text: "\n"},
+				{Class: "key", Text: "kind"},
+				{Text: ": "},
+				{Class: "literal", Text: "ENUM_VALUE"},
+				{Text: "\n"},
+				{Class: "key", Text: "sub"},
+				{Text: " {\n  "},
+				{Class: "key", Text: "[ext.field]"},
+				{Text: ": "},
+				{Class: "number", Text: "12"},
+				{Text: "\n}\n"},
+			},
+		},
+		{
+			name:    "json non-ASCII",
+			codec:   "json",
+			content: `{"k": "😀", "ü": ü, "x": 😀}`,
+			want: []highlightToken{
+				{Text: "{"},
+				{Class: "key", Text: `"k"`},
+				{Text: ": "},
+				{Class: "string", Text: `"😀"`},
+				{Text: ", "},
+				{Class: "key", Text: `"ü"`},
+				{Text: ": ü, "},
+				{Class: "key", Text: `"x"`},
+				{Text: ": 😀}"},
+			},
+		},
+		{
+			name:    "textproto non-ASCII",
+			codec:   "textproto",
+			content: "a: 1\n— x\nnäme: \"😀\"\n😀 ü\n",
+			want: []highlightToken{
+				{Class: "key", Text: "a"},
+				{Text: ": "},
+				{Class: "number", Text: "1"},
+				{Text: "\n— "},
+				{Class: "literal", Text: "x"},
+				{Text: "\n"},
+				{Class: "key", Text: "näme"},
+				{Text: ": "},
+				{Class: "string", Text: `"😀"`},
+				{Text: "\n😀 "},
+				{Class: "literal", Text: "ü"},
+				{Text: "\n"},
+			},
+		},
+		{
+			name:    "textproto invalid UTF-8",
+			codec:   "textproto",
+			content: "\xff\xfe: [\xff]",
+			want: []highlightToken{
+				{Text: "\xff\xfe: [\xff]"},
+			},
+		},
+		{
+			name:    "unterminated string",
+			codec:   "json",
+			content: `"abc`,
+			want: []highlightToken{
+				{Class: "string", Text: `"abc`},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := highlight(tc.codec, tc.content)
+
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("highlight() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
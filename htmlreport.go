@@ -0,0 +1,248 @@
+package aurum
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"os"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// HTMLReporter writes a self-contained HTML file for visually reviewing the
+// golden files of failed and updated assertions once closed. Old and new
+// content is shown side by side with syntax highlighting for JSON and text
+// protocol buffers. Images are embedded inline.
+type HTMLReporter struct {
+	path string
+
+	mu     sync.Mutex
+	items  []*htmlReportItem
+	byPath map[htmlReportKey]*htmlReportItem
+}
+
+var _ Reporter = (*HTMLReporter)(nil)
+
+// NewHTMLReporter returns a reporter writing to the file at the given path.
+func NewHTMLReporter(path string) *HTMLReporter {
+	return &HTMLReporter{
+		path:   path,
+		byPath: map[htmlReportKey]*htmlReportItem{},
+	}
+}
+
+type htmlReportKey struct {
+	test, path string
+}
+
+type htmlReportItem struct {
+	key      htmlReportKey
+	included bool
+	status   string
+	codec    string
+	old      []byte
+	new      []byte
+	hasData  bool
+	diff     string
+	message  string
+}
+
+func (r *HTMLReporter) item(test, path string) *htmlReportItem {
+	key := htmlReportKey{test, path}
+
+	it, ok := r.byPath[key]
+	if !ok {
+		it = &htmlReportItem{key: key}
+		r.byPath[key] = it
+	}
+
+	return it
+}
+
+// addItem includes an item in the report, once.
+func (r *HTMLReporter) addItem(it *htmlReportItem) {
+	if !it.included {
+		it.included = true
+		r.items = append(r.items, it)
+	}
+}
+
+func (r *HTMLReporter) Report(e Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch e := e.(type) {
+	case ComparisonEvent:
+		if !e.Equal {
+			r.item(e.Test, e.Path).diff = e.Diff
+		}
+
+	case UpdateWrittenEvent:
+		it := r.item(e.Test, e.Path)
+		it.status = "updated"
+		if e.Created {
+			it.status = "created"
+		}
+		it.codec, it.old, it.new, it.hasData = e.Codec, e.Old, e.New, true
+		r.addItem(it)
+
+	case UpdateSkippedEvent:
+		it := r.item(e.Test, e.Path)
+		it.status = "failed"
+		it.codec, it.old, it.new, it.hasData = e.Codec, e.Old, e.New, true
+		r.addItem(it)
+
+	case AssertionResult:
+		if e.Outcome != OutcomeFailed {
+			break
+		}
+
+		it := r.item(e.Test, e.Path)
+		if it.status == "" {
+			it.status = "failed"
+		}
+		it.message = e.Message
+		r.addItem(it)
+	}
+}
+
+type htmlReportContent struct {
+	Image  template.URL
+	Tokens []highlightToken
+	Binary int
+	Absent bool
+}
+
+type htmlReportEntry struct {
+	Test    string
+	Path    string
+	Status  string
+	Codec   string
+	HasData bool
+	Old     htmlReportContent
+	New     htmlReportContent
+	Diff    string
+	Message string
+}
+
+func newHTMLReportContent(codec string, data []byte) htmlReportContent {
+	switch {
+	case data == nil:
+		return htmlReportContent{Absent: true}
+
+	case codec == "png":
+		return htmlReportContent{
+			Image: template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(data)),
+		}
+
+	case !utf8.Valid(data) || bytes.IndexByte(data, 0) >= 0:
+		return htmlReportContent{Binary: len(data)}
+	}
+
+	return htmlReportContent{
+		Tokens: highlight(codec, string(data)),
+	}
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Golden file report</title>
+<style>
+body { font-family: sans-serif; margin: 1em 2em; }
+section { border: 1px solid #ccc; border-radius: 4px; margin: 1em 0; padding: 0 1em 1em; }
+h2 { font-size: 1.1em; font-family: monospace; }
+.status { border-radius: 3px; color: #fff; font-family: sans-serif; padding: 0 .4em; }
+.status-failed { background: #c62828; }
+.status-created { background: #2e7d32; }
+.status-updated { background: #1565c0; }
+.test { color: #555; }
+.columns { display: flex; gap: 1em; }
+.columns > div { flex: 1; min-width: 0; }
+pre { background: #f6f8fa; margin: 0; overflow: auto; padding: .5em; }
+img { background: repeating-conic-gradient(#ddd 0% 25%, #fff 0% 50%) 50% / 16px 16px; max-width: 100%; }
+.key { color: #0550ae; }
+.string { color: #0a3069; }
+.number { color: #953800; }
+.literal { color: #8250df; }
+.comment { color: #6e7781; font-style: italic; }
+</style>
+</head>
+<body>
+<h1>Golden file report</h1>
+{{- if not .}}
+<p>No failed or updated golden files.</p>
+{{- end}}
+{{- range .}}
+<section>
+<h2><span class="status status-{{.Status}}">{{.Status}}</span> {{.Path}}</h2>
+{{- if .Test}}
+<p class="test">Test: {{.Test}}{{if .Codec}}, codec: {{.Codec}}{{end}}</p>
+{{- end}}
+{{- if .Message}}
+<pre>{{.Message}}</pre>
+{{- end}}
+{{- if .HasData}}
+<div class="columns">
+<div><h3>Old</h3>{{template "content" .Old}}</div>
+<div><h3>New</h3>{{template "content" .New}}</div>
+</div>
+{{- end}}
+{{- if .Diff}}
+<details><summary>Difference</summary><pre>{{.Diff}}</pre></details>
+{{- end}}
+</section>
+{{- end}}
+</body>
+</html>
+{{define "content" -}}
+{{if .Absent}}<p><em>File does not exist.</em></p>
+{{- else if .Image}}<img src="{{.Image}}" alt="">
+{{- else if .Binary}}<p><em>{{.Binary}} bytes of binary data.</em></p>
+{{- else}}<pre>{{range .Tokens}}{{if .Class}}<span class="{{.Class}}">{{.Text}}</span>{{else}}{{.Text}}{{end}}{{end}}</pre>
+{{- end}}
+{{- end}}
+`))
+
+// Close writes the report file.
+func (r *HTMLReporter) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entries := []htmlReportEntry{}
+
+	for _, it := range r.items {
+		e := htmlReportEntry{
+			Test:    it.key.test,
+			Path:    it.key.path,
+			Status:  it.status,
+			Codec:   it.codec,
+			HasData: it.hasData,
+			Diff:    it.diff,
+			Message: it.message,
+		}
+
+		if it.hasData {
+			e.Old = newHTMLReportContent(it.codec, it.old)
+			e.New = newHTMLReportContent(it.codec, it.new)
+		}
+
+		// The message of failures with a difference repeats the diff.
+		if e.Diff != "" && strings.Contains(e.Message, e.Diff) {
+			e.Message = ""
+		}
+
+		entries = append(entries, e)
+	}
+
+	var buf bytes.Buffer
+
+	if err := htmlReportTemplate.Execute(&buf, entries); err != nil {
+		return fmt.Errorf("rendering HTML report: %w", err)
+	}
+
+	return os.WriteFile(r.path, buf.Bytes(), 0o644)
+}
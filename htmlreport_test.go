@@ -0,0 +1,88 @@
+package aurum
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHTMLReporter(t *testing.T) {
+	tmpdir := t.TempDir()
+	path := filepath.Join(tmpdir, "report.html")
+
+	r := NewHTMLReporter(path)
+
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	img.Set(0, 0, color.NRGBA{255, 0, 0, 255})
+
+	files := map[string][]byte{
+		"failed.json": []byte(`{"name": "<old>"}` + "\n"),
+		"passed.json": []byte("{}\n"),
+		"updated.txt": []byte("old\n"),
+	}
+
+	o := &Golden{
+		g:        &globalOptions{},
+		FS:       NewMemFS(files),
+		Codec:    &ExtensionCodec{},
+		Reporter: r,
+	}
+
+	tb := &fakeTB{name: "TestReport"}
+
+	o.Assert(tb, "failed.json", map[string]string{"name": "<new>"})
+	o.Assert(tb, "passed.json", map[string]string{})
+
+	o.g = &globalOptions{updatesEnabled: true}
+	o.Assert(tb, "updated.txt", "new\n")
+	o.Assert(tb, "created.png", img)
+
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() failed: %v", err)
+	}
+
+	got := string(data)
+
+	for _, want := range []string{
+		`<span class="status status-failed">failed</span> failed.json`,
+		`<span class="key">&#34;name&#34;</span>: <span class="string">&#34;&lt;old&gt;&#34;</span>`,
+		`<span class="string">&#34;\u003cnew\u003e&#34;</span>`,
+		`<summary>Difference</summary>`,
+		`<span class="status status-updated">updated</span> updated.txt`,
+		"<pre>old\n</pre>",
+		"<pre>new\n</pre>",
+		`<span class="status status-created">created</span> created.png`,
+		`<em>File does not exist.</em>`,
+		`<img src="data:image/png;base64,iVBORw0KGgo`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Report doesn't contain %q:\n%s", want, got)
+		}
+	}
+
+	if strings.Contains(got, "passed.json") {
+		t.Errorf("Report contains passed assertion:\n%s", got)
+	}
+}
+
+func TestHTMLReporterEmpty(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.html")
+
+	if err := NewHTMLReporter(path).Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+
+	if data, err := os.ReadFile(path); err != nil {
+		t.Fatalf("ReadFile() failed: %v", err)
+	} else if !strings.Contains(string(data), "No failed or updated golden files.") {
+		t.Errorf("Unexpected report:\n%s", data)
+	}
+}
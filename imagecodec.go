@@ -0,0 +1,119 @@
+package aurum
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"reflect"
+
+	"github.com/hansmi/aurum/internal/codecutil"
+)
+
+var imageType = reflect.TypeOf((*image.Image)(nil)).Elem()
+
+// ImageCodec stores images in the PNG format. Values must implement
+// [image.Image].
+//
+// Decoded images are converted to the type of the asserted value if
+// necessary. Supported are the [image.RGBA], [image.RGBA64], [image.NRGBA],
+// [image.NRGBA64], [image.Gray], [image.Gray16] and [image.Paletted] types.
+// The origin of the image bounds is not retained.
+type ImageCodec struct {
+	// Defaults to [png.DefaultCompression].
+	CompressionLevel png.CompressionLevel
+}
+
+var _ Codec = (*ImageCodec)(nil)
+
+func (c *ImageCodec) Marshal(v any) ([]byte, error) {
+	rv, _, err := codecutil.PrepareMarshalValue(v)
+	if err != nil {
+		return nil, err
+	}
+
+	img, ok := rv.Interface().(image.Image)
+	if !ok || rv.IsZero() {
+		return nil, fmt.Errorf("%w: marshalling %T as image is not supported", os.ErrInvalid, v)
+	}
+
+	var buf bytes.Buffer
+
+	enc := png.Encoder{
+		CompressionLevel: c.CompressionLevel,
+	}
+
+	if err := enc.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// convertImage returns an image of the given type with the same content.
+func convertImage(img image.Image, t reflect.Type) (image.Image, error) {
+	if reflect.TypeOf(img).AssignableTo(t) {
+		return img, nil
+	}
+
+	var dst draw.Image
+
+	b := img.Bounds()
+
+	switch t {
+	case reflect.TypeOf((*image.RGBA)(nil)):
+		dst = image.NewRGBA(b)
+	case reflect.TypeOf((*image.RGBA64)(nil)):
+		dst = image.NewRGBA64(b)
+	case reflect.TypeOf((*image.NRGBA)(nil)):
+		dst = image.NewNRGBA(b)
+	case reflect.TypeOf((*image.NRGBA64)(nil)):
+		dst = image.NewNRGBA64(b)
+	case reflect.TypeOf((*image.Gray)(nil)):
+		dst = image.NewGray(b)
+	case reflect.TypeOf((*image.Gray16)(nil)):
+		dst = image.NewGray16(b)
+	case reflect.TypeOf((*image.Paletted)(nil)):
+		p, ok := img.ColorModel().(color.Palette)
+		if !ok {
+			return nil, fmt.Errorf("%w: image of type %T has no palette", os.ErrInvalid, img)
+		}
+
+		dst = image.NewPaletted(b, p)
+	default:
+		return nil, fmt.Errorf("%w: converting image to %s is not supported", os.ErrInvalid, t)
+	}
+
+	draw.Draw(dst, b, img, b.Min, draw.Src)
+
+	return dst, nil
+}
+
+func (c *ImageCodec) Unmarshal(data []byte, v any) error {
+	rv, _, err := codecutil.PrepareUnmarshalDest(v)
+	if err != nil {
+		return err
+	}
+
+	dest := rv.Elem()
+
+	if !(dest.Type() == imageType || dest.Type().Implements(imageType)) {
+		return fmt.Errorf("%w: unmarshalling image into %T is not supported", os.ErrInvalid, v)
+	}
+
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	if img, err = convertImage(img, dest.Type()); err != nil {
+		return err
+	}
+
+	dest.Set(reflect.ValueOf(img))
+
+	return nil
+}
@@ -0,0 +1,162 @@
+package aurum
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/hansmi/aurum/internal/codectest"
+	"github.com/hansmi/aurum/internal/codecutil"
+)
+
+func newTestImage(width, height int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{uint8(x * 16), uint8(y * 16), 128, 255})
+		}
+	}
+
+	return img
+}
+
+func TestImageCodec(t *testing.T) {
+	rgba := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	rgba.SetRGBA(1, 1, color.RGBA{1, 2, 3, 255})
+
+	gray := image.NewGray(image.Rect(0, 0, 2, 2))
+	gray.SetGray(0, 1, color.Gray{200})
+
+	paletted := image.NewPaletted(image.Rect(0, 0, 2, 1), color.Palette{color.RGBA{0, 0, 0, 255}, color.RGBA{255, 255, 255, 255}})
+	paletted.SetColorIndex(1, 0, 1)
+
+	codectest.AssertAll(t, &ImageCodec{}, []codectest.Case{
+		{
+			Name:  "nrgba",
+			Value: newTestImage(8, 4),
+		},
+		{
+			Name:  "rgba",
+			Value: rgba,
+		},
+		{
+			Name:  "gray",
+			Value: gray,
+		},
+		{
+			Name:  "paletted",
+			Value: paletted,
+		},
+		{
+			Name:           "string",
+			Value:          "hello",
+			WantMarshalErr: os.ErrInvalid,
+		},
+	})
+}
+
+func TestImageCodecConvert(t *testing.T) {
+	var c ImageCodec
+
+	data, err := c.Marshal(newTestImage(4, 4))
+	if err != nil {
+		t.Fatalf("Marshal() failed: %v", err)
+	}
+
+	_, valueType := codecutil.NormalizeValue(image.NewRGBA64(image.Rectangle{}))
+
+	got, err := codecutil.Unmarshal(&c, data, valueType)
+	if err != nil {
+		t.Fatalf("Unmarshal() failed: %v", err)
+	}
+
+	if err := (&ImageComparer{}).Equal(newTestImage(4, 4), got); err != nil {
+		t.Errorf("Equal() failed: %v", err)
+	}
+}
+
+func TestImageComparer(t *testing.T) {
+	modified := newTestImage(4, 4)
+	modified.SetNRGBA(0, 0, color.NRGBA{3, 0, 128, 255})
+	modified.SetNRGBA(1, 1, color.NRGBA{0, 0, 0, 255})
+
+	for _, tc := range []struct {
+		name     string
+		comparer ImageComparer
+		want     any
+		got      any
+		wantErr  error
+		wantDiff bool
+	}{
+		{
+			name: "equal",
+			want: newTestImage(4, 4),
+			got:  newTestImage(4, 4),
+		},
+		{
+			name: "nil",
+			want: (*image.NRGBA)(nil),
+			got:  (*image.RGBA)(nil),
+		},
+		{
+			name:    "nil and non-nil",
+			want:    (*image.NRGBA)(nil),
+			got:     newTestImage(1, 1),
+			wantErr: ErrValueDifference,
+		},
+		{
+			name:    "size",
+			want:    newTestImage(4, 4),
+			got:     newTestImage(4, 3),
+			wantErr: ErrValueDifference,
+		},
+		{
+			name:     "pixels",
+			want:     newTestImage(4, 4),
+			got:      modified,
+			wantErr:  ErrValueDifference,
+			wantDiff: true,
+		},
+		{
+			name:     "tolerance",
+			comparer: ImageComparer{Tolerance: 3},
+			want:     newTestImage(4, 4),
+			got:      modified,
+			wantErr:  ErrValueDifference,
+			wantDiff: true,
+		},
+		{
+			name:     "tolerance and max pixels",
+			comparer: ImageComparer{Tolerance: 3, MaxDifferentPixels: 1},
+			want:     newTestImage(4, 4),
+			got:      modified,
+		},
+		{
+			name:    "not an image",
+			want:    "text",
+			got:     newTestImage(1, 1),
+			wantErr: os.ErrInvalid,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var written []string
+
+			err := tc.comparer.equalWithArtifacts(tc.want, tc.got, func(suffix string, data []byte) (string, error) {
+				written = append(written, suffix)
+				return "name" + suffix, nil
+			})
+
+			if diff := cmp.Diff(tc.wantErr, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("Error diff (-want +got):\n%s", diff)
+			}
+
+			if diff := cmp.Diff(tc.wantDiff, len(written) > 0); diff != "" {
+				t.Errorf("Difference image written (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package aurum
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"reflect"
+)
+
+// ImageComparer compares images pixel by pixel. Small differences, e.g. from
+// different encoder versions or anti-aliasing, can be tolerated.
+//
+// When used by [Golden] an image highlighting differing pixels in red is
+// written next to the golden file if the images are not equal. Difference
+// images are only written for golden files stored in a directory, not when
+// updating golden files. They're removed once the images are equal.
+type ImageComparer struct {
+	// Maximum difference per color channel on an 8-bit scale for pixels to be
+	// considered equal.
+	Tolerance uint8
+
+	// Maximum number of pixels allowed to exceed the tolerance.
+	MaxDifferentPixels int
+
+	// Suffix appended to the golden filename for the difference image.
+	//
+	// Defaults to ".diff.png".
+	DiffSuffix string
+}
+
+var _ Comparer = (*ImageComparer)(nil)
+var _ artifactComparer = (*ImageComparer)(nil)
+
+func isNilImage(img image.Image) bool {
+	rv := reflect.ValueOf(img)
+
+	return rv.Kind() == reflect.Pointer && rv.IsNil()
+}
+
+func (c *ImageComparer) pixelEqual(a, b color.Color) bool {
+	ar, ag, ab, aa := a.RGBA()
+	br, bg, bb, ba := b.RGBA()
+
+	for _, pair := range [][2]uint32{{ar, br}, {ag, bg}, {ab, bb}, {aa, ba}} {
+		x, y := pair[0]>>8, pair[1]>>8
+
+		if max(x, y)-min(x, y) > uint32(c.Tolerance) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// compare returns the number of differing pixels and an image highlighting
+// them.
+func (c *ImageComparer) compare(want, got image.Image) (int, *image.NRGBA) {
+	wb, gb := want.Bounds(), got.Bounds()
+
+	diff := image.NewNRGBA(image.Rect(0, 0, gb.Dx(), gb.Dy()))
+	count := 0
+
+	for y := 0; y < gb.Dy(); y++ {
+		for x := 0; x < gb.Dx(); x++ {
+			gc := got.At(gb.Min.X+x, gb.Min.Y+y)
+
+			if c.pixelEqual(want.At(wb.Min.X+x, wb.Min.Y+y), gc) {
+				// Faded grayscale version of the original.
+				gray := color.GrayModel.Convert(gc).(color.Gray)
+				diff.SetNRGBA(x, y, color.NRGBA{gray.Y, gray.Y, gray.Y, 64})
+			} else {
+				diff.SetNRGBA(x, y, color.NRGBA{255, 0, 0, 255})
+				count++
+			}
+		}
+	}
+
+	return count, diff
+}
+
+func (c *ImageComparer) diffSuffix() string {
+	if c.DiffSuffix == "" {
+		return ".diff.png"
+	}
+
+	return c.DiffSuffix
+}
+
+func (c *ImageComparer) artifactSuffixes() []string {
+	return []string{c.diffSuffix()}
+}
+
+func (c *ImageComparer) equalWithArtifacts(want, got any, write artifactWriter) error {
+	wantImg, wantOk := want.(image.Image)
+	gotImg, gotOk := got.(image.Image)
+
+	if !(wantOk && gotOk) {
+		return fmt.Errorf("%w: comparing %T and %T as images is not supported", os.ErrInvalid, want, got)
+	}
+
+	if wantNil, gotNil := isNilImage(wantImg), isNilImage(gotImg); wantNil || gotNil {
+		if wantNil == gotNil {
+			return nil
+		}
+
+		return fmt.Errorf("%w: want nil image %t, got nil image %t", ErrValueDifference, wantNil, gotNil)
+	}
+
+	if ws, gs := wantImg.Bounds().Size(), gotImg.Bounds().Size(); ws != gs {
+		return fmt.Errorf("%w: image size differs, want %v, got %v", ErrValueDifference, ws, gs)
+	}
+
+	count, diff := c.compare(wantImg, gotImg)
+
+	if count <= c.MaxDifferentPixels {
+		return nil
+	}
+
+	err := fmt.Errorf("%w: %d pixels differ by more than %d per channel (%d allowed)",
+		ErrValueDifference, count, c.Tolerance, c.MaxDifferentPixels)
+
+	if write != nil {
+		var buf bytes.Buffer
+
+		if encErr := png.Encode(&buf, diff); encErr != nil {
+			return fmt.Errorf("%w; encoding difference image: %v", err, encErr)
+		}
+
+		name, writeErr := write(c.diffSuffix(), buf.Bytes())
+		if writeErr != nil {
+			return fmt.Errorf("%w; writing difference image: %v", err, writeErr)
+		}
+
+		return fmt.Errorf("%w; difference image written to %q", err, name)
+	}
+
+	return err
+}
+
+func (c *ImageComparer) Equal(want, got any) error {
+	return c.equalWithArtifacts(want, got, nil)
+}
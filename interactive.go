@@ -0,0 +1,250 @@
+package aurum
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Value of the update flag enabling interactive updates.
+const updateFlagInteractive = "interactive"
+
+// Default name of the flag for the file containing update decisions (see
+// [WithDecisionsFile]).
+const DefaultDecisionsFlagName = "update_golden_files_decisions"
+
+// Reason given in [UpdateSkippedEvent] for updates rejected in interactive
+// mode.
+const updateRejectedReason = "update rejected"
+
+var errUpdateRejected = errors.New("golden file update rejected")
+
+// updateDecider decides whether a golden file should be updated. The
+// description explains the change, e.g. using a diff.
+type updateDecider interface {
+	decide(path, description string) bool
+}
+
+// promptDecider asks the user for each update.
+type promptDecider struct {
+	mu  sync.Mutex
+	in  *bufio.Reader
+	out io.Writer
+
+	// Whether all remaining updates are decided after choosing "all" or
+	// "quit".
+	decided  bool
+	decision bool
+}
+
+func newPromptDecider(in io.Reader, out io.Writer) *promptDecider {
+	return &promptDecider{
+		in:  bufio.NewReader(in),
+		out: out,
+	}
+}
+
+func (d *promptDecider) decide(path, description string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.decided {
+		return d.decision
+	}
+
+	fmt.Fprintf(d.out, "\n%s\n", strings.TrimRight(description, "\n"))
+
+	for {
+		fmt.Fprintf(d.out, "Update golden file %q? [y]es, [n]o, [a]ll, [q]uit: ", path)
+
+		line, err := d.in.ReadString('\n')
+
+		switch strings.ToLower(strings.TrimSpace(line)) {
+		case "y", "yes":
+			return true
+		case "n", "no":
+			return false
+		case "a", "all":
+			d.decided, d.decision = true, true
+			return true
+		case "q", "quit":
+			d.decided, d.decision = true, false
+			return false
+		}
+
+		if err != nil {
+			// Reject all remaining updates when input is exhausted.
+			fmt.Fprintln(d.out)
+			d.decided, d.decision = true, false
+			return false
+		}
+	}
+}
+
+type decisionRule struct {
+	accept  bool
+	pattern string
+}
+
+// fileDecider decides based on rules read from a file. Each non-empty line
+// not starting with "#" consists of "accept" or "reject" followed by
+// a pattern as understood by [path.Match], e.g. "accept testdata/*.json".
+// Paths use forward slashes. The first matching rule wins; updates matching
+// no rule are rejected.
+type fileDecider struct {
+	rules []decisionRule
+}
+
+func parseDecisions(r io.Reader) (*fileDecider, error) {
+	var d fileDecider
+
+	s := bufio.NewScanner(r)
+
+	for lineno := 1; s.Scan(); lineno++ {
+		line := strings.TrimSpace(s.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		action, pattern, _ := strings.Cut(line, " ")
+		pattern = strings.TrimSpace(pattern)
+
+		var rule decisionRule
+
+		switch action {
+		case "accept":
+			rule.accept = true
+		case "reject":
+		default:
+			return nil, fmt.Errorf("line %d: %w: unknown action %q", lineno, os.ErrInvalid, action)
+		}
+
+		if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+			return nil, fmt.Errorf("line %d: %w: invalid pattern %q", lineno, os.ErrInvalid, pattern)
+		}
+
+		rule.pattern = pattern
+
+		d.rules = append(d.rules, rule)
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	return &d, nil
+}
+
+func (d *fileDecider) decide(p, _ string) bool {
+	p = filepath.ToSlash(p)
+
+	for _, rule := range d.rules {
+		if ok, _ := path.Match(rule.pattern, p); ok {
+			return rule.accept
+		}
+	}
+
+	return false
+}
+
+// rejectDecider rejects all updates.
+type rejectDecider struct{}
+
+func (rejectDecider) decide(string, string) bool {
+	return false
+}
+
+// isTerminal reports whether the file is a character device other than the
+// null device.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+
+	null, err := os.Stat(os.DevNull)
+
+	return err != nil || !os.SameFile(fi, null)
+}
+
+// openTerminal opens the controlling terminal of the process.
+func openTerminal() (*os.File, error) {
+	return os.OpenFile("/dev/tty", os.O_RDWR, 0)
+}
+
+// newUpdateDecider returns the decider for interactive updates. A decisions
+// file takes precedence over prompting.
+func newUpdateDecider(decisionsFile string, logf logFunc) (updateDecider, error) {
+	if decisionsFile != "" {
+		fh, err := os.Open(decisionsFile)
+		if err != nil {
+			return nil, err
+		}
+
+		defer fh.Close()
+
+		d, err := parseDecisions(fh)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", decisionsFile, err)
+		}
+
+		return d, nil
+	}
+
+	return newTerminalDecider(os.Stdin, os.Stdout, openTerminal, logf), nil
+}
+
+// newTerminalDecider returns a decider prompting on standard input if it's
+// a terminal. "go test" runs test binaries with standard input connected to
+// the null device and output captured, so the controlling terminal is used
+// instead if available. Updates are rejected if neither is available.
+func newTerminalDecider(stdin, stdout *os.File, openTerm func() (*os.File, error), logf logFunc) updateDecider {
+	if isTerminal(stdin) {
+		return newPromptDecider(stdin, stdout)
+	}
+
+	tty, err := openTerm()
+	if err == nil {
+		return newPromptDecider(tty, tty)
+	}
+
+	logf(`Standard input is not a terminal, e.g. because "go test" connects it to the null device, and the controlling terminal is unavailable (%v). `+
+		`Rejecting all golden file updates. Run the test binary built using "go test -c" directly or use -%s to provide decisions.`,
+		err, DefaultDecisionsFlagName)
+
+	return rejectDecider{}
+}
+
+// approveUpdate decides whether a golden file may be written. All updates are
+// approved unless interactive updates are enabled.
+func (g *globalOptions) approveUpdate(path, description string, logf logFunc) (bool, error) {
+	g.mu.Lock()
+
+	if !g.interactive {
+		g.mu.Unlock()
+		return true, nil
+	}
+
+	if g.decider == nil {
+		d, err := newUpdateDecider(g.decisionsFile, logf)
+		if err != nil {
+			g.mu.Unlock()
+			return false, fmt.Errorf("reading update decisions: %w", err)
+		}
+
+		g.decider = d
+	}
+
+	d := g.decider
+
+	g.mu.Unlock()
+
+	return d.decide(path, description), nil
+}
@@ -0,0 +1,272 @@
+package aurum
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPromptDecider(t *testing.T) {
+	var out strings.Builder
+
+	d := newPromptDecider(strings.NewReader("x\ny\nNo\na\n"), &out)
+
+	var got []bool
+
+	for _, p := range []string{"first", "second", "third", "fourth"} {
+		got = append(got, d.decide(p, "diff for "+p))
+	}
+
+	if diff := cmp.Diff([]bool{true, false, true, true}, got); diff != "" {
+		t.Errorf("Decisions diff (-want +got):\n%s", diff)
+	}
+
+	for _, want := range []string{
+		"\ndiff for first\n",
+		`Update golden file "first"?`,
+		`Update golden file "third"?`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Output doesn't contain %q:\n%s", want, out.String())
+		}
+	}
+
+	if strings.Contains(out.String(), "fourth") {
+		t.Errorf("Prompted after accepting all:\n%s", out.String())
+	}
+}
+
+func TestPromptDeciderEOF(t *testing.T) {
+	d := newPromptDecider(strings.NewReader("q"), &strings.Builder{})
+
+	if d.decide("first", "") || d.decide("second", "") {
+		t.Errorf("Update accepted after quitting")
+	}
+
+	d = newPromptDecider(strings.NewReader(""), &strings.Builder{})
+
+	if d.decide("first", "") {
+		t.Errorf("Update accepted without input")
+	}
+}
+
+func TestParseDecisions(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		input   string
+		paths   map[string]bool
+		wantErr error
+	}{
+		{
+			name: "empty",
+			paths: map[string]bool{
+				"testdata/a": false,
+			},
+		},
+		{
+			name: "rules",
+			input: `
+# Comment
+reject testdata/skip.json
+accept testdata/*.json
+  accept   other/*
+`,
+			paths: map[string]bool{
+				"testdata/a.json":    true,
+				"testdata/skip.json": false,
+				"testdata/a.txt":     false,
+				"other/b":            true,
+				"other/sub/b":        false,
+			},
+		},
+		{
+			name:    "unknown action",
+			input:   "maybe testdata/*",
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name:    "missing pattern",
+			input:   "accept",
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name:    "invalid pattern",
+			input:   "accept [",
+			wantErr: os.ErrInvalid,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			d, err := parseDecisions(strings.NewReader(tc.input))
+
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("parseDecisions() returned %v, want %v", err, tc.wantErr)
+			}
+
+			for p, want := range tc.paths {
+				if got := d.decide(filepath.FromSlash(p), ""); got != want {
+					t.Errorf("decide(%q) returned %t, want %t", p, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewUpdateDecider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "decisions")
+
+	if _, err := newUpdateDecider(path, t.Logf); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("newUpdateDecider() returned %v, want %v", err, os.ErrNotExist)
+	}
+
+	if err := os.WriteFile(path, []byte("accept *\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if d, err := newUpdateDecider(path, t.Logf); err != nil {
+		t.Errorf("newUpdateDecider() failed: %v", err)
+	} else if !d.decide("file", "") {
+		t.Errorf("Update rejected")
+	}
+}
+
+func TestNewTerminalDecider(t *testing.T) {
+	stdin, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer stdin.Close()
+
+	t.Run("no terminal", func(t *testing.T) {
+		var logs []string
+
+		d := newTerminalDecider(stdin, os.Stdout, func() (*os.File, error) {
+			return nil, os.ErrNotExist
+		}, func(format string, args ...any) {
+			logs = append(logs, fmt.Sprintf(format, args...))
+		})
+
+		if _, ok := d.(rejectDecider); !ok {
+			t.Errorf("newTerminalDecider() returned %#v, want rejectDecider", d)
+		}
+
+		if len(logs) != 1 || !strings.Contains(logs[0], `"go test -c"`) {
+			t.Errorf("Missing explanation in logs: %q", logs)
+		}
+	})
+
+	t.Run("controlling terminal", func(t *testing.T) {
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		defer r.Close()
+
+		if _, err := w.WriteString("y\n"); err != nil {
+			t.Fatal(err)
+		}
+
+		w.Close()
+
+		d := newTerminalDecider(stdin, os.Stdout, func() (*os.File, error) {
+			return r, nil
+		}, t.Logf)
+
+		if !d.decide("file", "") {
+			t.Errorf("Update rejected")
+		}
+	})
+}
+
+func TestGoldenAssertInteractive(t *testing.T) {
+	m := NewMemFS(map[string][]byte{
+		"accepted": []byte("\"old\"\n"),
+		"rejected": []byte("\"old\"\n"),
+	})
+
+	d, err := parseDecisions(strings.NewReader("accept accepted\naccept created\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := &fakeReporter{}
+
+	o := &Golden{
+		g: &globalOptions{
+			updatesEnabled: true,
+			interactive:    true,
+			decider:        d,
+		},
+		FS:       m,
+		Reporter: r,
+	}
+
+	for _, name := range []string{"accepted", "created"} {
+		if err := o.assert(name, "new", t.Logf); err != nil {
+			t.Errorf("assert(%q) failed: %v", name, err)
+		}
+	}
+
+	for _, name := range []string{"rejected", "missing"} {
+		if err := o.assert(name, "new", t.Logf); !errors.Is(err, errUpdateRejected) {
+			t.Errorf("assert(%q) returned %v, want %v", name, err, errUpdateRejected)
+		}
+	}
+
+	if err := o.assert("rejected", "new", t.Logf); !errors.Is(err, ErrValueDifference) {
+		t.Errorf("assert() returned %v, want %v", err, ErrValueDifference)
+	}
+
+	var written []string
+
+	for _, w := range m.Writes() {
+		written = append(written, w.Name)
+	}
+
+	if diff := cmp.Diff([]string{"accepted", "created"}, written); diff != "" {
+		t.Errorf("Written files diff (-want +got):\n%s", diff)
+	}
+
+	var skipped []string
+
+	for _, e := range r.events {
+		if e, ok := e.(UpdateSkippedEvent); ok && e.Reason == updateRejectedReason {
+			skipped = append(skipped, e.Path)
+		}
+	}
+
+	if diff := cmp.Diff([]string{"rejected", "missing", "rejected"}, skipped); diff != "" {
+		t.Errorf("Skipped updates diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestSnapshotInteractive(t *testing.T) {
+	m := NewMemFS(nil)
+
+	o := &Golden{
+		g: &globalOptions{
+			updatesEnabled: true,
+			interactive:    true,
+			decider:        rejectDecider{},
+		},
+		FS: m,
+	}
+
+	tb := &fakeTB{name: "snapshot"}
+	o.Snapshot(tb).Add("entry", 1)
+	tb.runCleanups()
+
+	if len(tb.errors) == 0 || tb.errors[0] != errUpdateRejected.Error() {
+		t.Errorf("Unexpected errors: %q", tb.errors)
+	}
+
+	if writes := m.Writes(); len(writes) > 0 {
+		t.Errorf("Rejected snapshot was written: %+v", writes)
+	}
+}
@@ -0,0 +1,173 @@
+// Package txtar implements a trivial text-based file archive format
+// compatible with golang.org/x/tools/txtar.
+//
+// An archive consists of a comment followed by zero or more files. Each file
+// starts with a marker line of the form "-- FILENAME --".
+//
+// The format requires file data to end in a newline. To store data without
+// a final newline losslessly a newline is appended and the file name is
+// recorded on a line of the form "no-final-newline: FILENAME" at the end of
+// the comment. Parse removes such lines from the comment again.
+package txtar
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrMarker is returned by Format if the comment or the data of a file
+// contains a line looking like a file marker. Such data can't be stored
+// without changing the archive structure.
+var ErrMarker = errors.New("data contains a txtar file marker line")
+
+const noFinalNewlinePrefix = "no-final-newline: "
+
+var newlineMarker = []byte("\n-- ")
+var marker = []byte("-- ")
+var markerEnd = []byte(" --")
+
+// Archive is a collection of files.
+type Archive struct {
+	Comment []byte
+	Files   []File
+}
+
+// File is a single file in an archive.
+type File struct {
+	Name string
+	Data []byte
+}
+
+// Format returns the serialized form of an archive. An error wrapping
+// [ErrMarker] is returned if the comment or a file contains a marker line.
+func Format(a *Archive) ([]byte, error) {
+	if hasMarker(a.Comment) {
+		return nil, fmt.Errorf("%w in comment", ErrMarker)
+	}
+
+	for _, f := range a.Files {
+		if hasMarker(f.Data) {
+			return nil, fmt.Errorf("%w in file %q", ErrMarker, f.Name)
+		}
+	}
+
+	var buf bytes.Buffer
+
+	buf.Write(fixNL(a.Comment))
+
+	for _, f := range a.Files {
+		if len(f.Data) > 0 && f.Data[len(f.Data)-1] != '\n' {
+			buf.WriteString(noFinalNewlinePrefix)
+			buf.WriteString(f.Name)
+			buf.WriteByte('\n')
+		}
+	}
+
+	for _, f := range a.Files {
+		buf.WriteString("-- ")
+		buf.WriteString(f.Name)
+		buf.WriteString(" --\n")
+		buf.Write(fixNL(f.Data))
+	}
+
+	return buf.Bytes(), nil
+}
+
+// hasMarker returns whether data contains a file marker line.
+func hasMarker(data []byte) bool {
+	_, name, _ := findFileMarker(data)
+
+	return name != ""
+}
+
+// Parse parses the serialized form of an archive. The returned archive
+// references the data slice.
+func Parse(data []byte) *Archive {
+	a := new(Archive)
+
+	var name string
+
+	a.Comment, name, data = findFileMarker(data)
+
+	for name != "" {
+		f := File{Name: name}
+		f.Data, name, data = findFileMarker(data)
+		a.Files = append(a.Files, f)
+	}
+
+	var comment bytes.Buffer
+	var noFinalNewline []string
+
+	for _, line := range bytes.SplitAfter(a.Comment, []byte{'\n'}) {
+		if name, ok := strings.CutPrefix(strings.TrimSuffix(string(line), "\n"), noFinalNewlinePrefix); ok {
+			noFinalNewline = append(noFinalNewline, name)
+		} else {
+			comment.Write(line)
+		}
+	}
+
+	if len(noFinalNewline) > 0 {
+		a.Comment = comment.Bytes()
+
+		for i, f := range a.Files {
+			for _, name := range noFinalNewline {
+				if f.Name == name {
+					a.Files[i].Data = bytes.TrimSuffix(f.Data, []byte{'\n'})
+				}
+			}
+		}
+	}
+
+	return a
+}
+
+// findFileMarker finds the next file marker in data and returns the data
+// before the marker, the file name and the data after the marker line.
+func findFileMarker(data []byte) (before []byte, name string, after []byte) {
+	var i int
+
+	for {
+		if name, after = isMarker(data[i:]); name != "" {
+			return data[:i], name, after
+		}
+
+		j := bytes.Index(data[i:], newlineMarker)
+		if j < 0 {
+			return fixNL(data), "", nil
+		}
+
+		i += j + 1
+	}
+}
+
+// isMarker checks whether data begins with a file marker line.
+func isMarker(data []byte) (name string, after []byte) {
+	if !bytes.HasPrefix(data, marker) {
+		return "", nil
+	}
+
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		data, after = data[:i], data[i+1:]
+	}
+
+	if !(bytes.HasSuffix(data, markerEnd) && len(data) >= len(marker)+len(markerEnd)) {
+		return "", nil
+	}
+
+	return strings.TrimSpace(string(data[len(marker) : len(data)-len(markerEnd)])), after
+}
+
+// fixNL returns data with a final newline, if necessary.
+func fixNL(data []byte) []byte {
+	if len(data) == 0 || data[len(data)-1] == '\n' {
+		return data
+	}
+
+	d := make([]byte, len(data)+1)
+	copy(d, data)
+	d[len(data)] = '\n'
+
+	return d
+}
@@ -0,0 +1,99 @@
+package txtar
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		archive Archive
+		want    string
+	}{
+		{name: "empty"},
+		{
+			name: "comment only",
+			archive: Archive{
+				Comment: []byte("comment\n"),
+			},
+			want: "comment\n",
+		},
+		{
+			name: "files",
+			archive: Archive{
+				Comment: []byte("comment\n"),
+				Files: []File{
+					{Name: "a", Data: []byte("first\n")},
+					{Name: "b"},
+					{Name: "c d", Data: []byte("line\n")},
+				},
+			},
+			want: "comment\n-- a --\nfirst\n-- b --\n-- c d --\nline\n",
+		},
+		{
+			name: "no final newline",
+			archive: Archive{
+				Files: []File{
+					{Name: "a", Data: []byte("first")},
+					{Name: "b", Data: []byte("second\n")},
+				},
+			},
+			want: "no-final-newline: a\n-- a --\nfirst\n-- b --\nsecond\n",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Format(&tc.archive)
+			if err != nil {
+				t.Fatalf("Format() failed: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.want, string(got)); diff != "" {
+				t.Errorf("Format() diff (-want +got):\n%s", diff)
+			}
+
+			if diff := cmp.Diff(&tc.archive, Parse(got), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("Parse() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFormatMarker(t *testing.T) {
+	for _, a := range []Archive{
+		{Comment: []byte("-- a --\n")},
+		{Files: []File{{Name: "a", Data: []byte("-- b --")}}},
+		{Files: []File{{Name: "a", Data: []byte("line\n-- b --\nmore\n")}}},
+	} {
+		if _, err := Format(&a); !errors.Is(err, ErrMarker) {
+			t.Errorf("Format(%q) returned %v, want %v", a, err, ErrMarker)
+		}
+	}
+
+	for _, data := range []string{"-- a", "line\n --  b --\n", "a -- b --\n"} {
+		a := Archive{Files: []File{{Name: "a", Data: []byte(data)}}}
+
+		if _, err := Format(&a); err != nil {
+			t.Errorf("Format(%q) failed: %v", data, err)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	got := Parse([]byte("comment\n--  spaced name  --\ndata\n-- x --\nno newline"))
+
+	want := &Archive{
+		Comment: []byte("comment\n"),
+		Files: []File{
+			{Name: "spaced name", Data: []byte("data\n")},
+			{Name: "x", Data: []byte("no newline\n")},
+		},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Parse() diff (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,100 @@
+package aurum
+
+import (
+	"encoding/xml"
+	"os"
+	"sync"
+)
+
+// JUnitReporter writes the results of all assertions to a file in the JUnit
+// XML format once closed. Each golden file assertion becomes a test case
+// named after the golden file path with the test name as its class name.
+type JUnitReporter struct {
+	path string
+
+	mu      sync.Mutex
+	results []AssertionResult
+}
+
+var _ Reporter = (*JUnitReporter)(nil)
+
+// NewJUnitReporter returns a reporter writing to the file at the given path.
+func NewJUnitReporter(path string) *JUnitReporter {
+	return &JUnitReporter{
+		path: path,
+	}
+}
+
+func (r *JUnitReporter) Report(e Event) {
+	if result, ok := e.(AssertionResult); ok {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		r.results = append(r.results, result)
+	}
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitTestSuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name `xml:"testsuites"`
+	Suites  []junitTestSuite
+}
+
+// Close writes the report file.
+func (r *JUnitReporter) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	suite := junitTestSuite{
+		Name:      "aurum",
+		Tests:     len(r.results),
+		TestCases: []junitTestCase{},
+	}
+
+	for _, result := range r.results {
+		tc := junitTestCase{
+			Name:      result.Path,
+			ClassName: result.Test,
+		}
+
+		if result.Outcome == OutcomeFailed {
+			tc.Failure = &junitFailure{
+				Message: "golden file assertion failed",
+				Text:    result.Message,
+			}
+
+			suite.Failures++
+		}
+
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+
+	data, err := xml.MarshalIndent(junitTestSuites{
+		Suites: []junitTestSuite{suite},
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	data = append([]byte(xml.Header), data...)
+
+	return os.WriteFile(r.path, append(data, '\n'), 0o644)
+}
@@ -0,0 +1,43 @@
+package aurum
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestJUnitReporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "junit.xml")
+
+	r := NewJUnitReporter(path)
+	r.Report(AssertionResult{Test: "TestA", Path: "testdata/a", Outcome: OutcomePassed})
+	r.Report(AssertionResult{Test: "TestB", Path: "testdata/b", Outcome: OutcomeFailed, Message: "values <differ>"})
+	r.Report(AssertionResult{Test: "TestC", Path: "testdata/c", Outcome: OutcomeCreated})
+
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() failed: %v", err)
+	}
+
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="aurum" tests="3" failures="1">
+    <testcase name="testdata/a" classname="TestA"></testcase>
+    <testcase name="testdata/b" classname="TestB">
+      <failure message="golden file assertion failed">values &lt;differ&gt;</failure>
+    </testcase>
+    <testcase name="testdata/c" classname="TestC"></testcase>
+  </testsuite>
+</testsuites>
+`
+
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("Report diff (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,222 @@
+package aurum
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+)
+
+// migratingFS is implemented by filesystems moving golden files to a new
+// location when updating.
+type migratingFS interface {
+	migrate(name string) (bool, error)
+}
+
+// LegacyLayout describes how golden files are named by other golden file
+// libraries. Names passed to [Golden.Assert] are used without URL-escaping,
+// i.e. slashes refer to subdirectories.
+type LegacyLayout struct {
+	// Suffix appended to golden file names, e.g. ".golden".
+	Suffix string
+
+	// Store golden files in a directory named after the top-level test.
+	TestNameForDir bool
+
+	// Store golden files in a directory named after the first-level subtest,
+	// if any. Combined with [LegacyLayout.TestNameForDir] the subtest
+	// directory is nested in the test directory.
+	SubTestNameForDir bool
+}
+
+// GoldieLayout is the default layout of [github.com/sebdah/goldie/v2]. Set
+// [LegacyLayout.TestNameForDir] and [LegacyLayout.SubTestNameForDir] to
+// mirror the corresponding goldie options.
+var GoldieLayout = LegacyLayout{Suffix: ".golden"}
+
+// GotestToolsLayout is the layout of [gotest.tools/v3/golden]. File names
+// are used as given, including any ".golden" suffix.
+var GotestToolsLayout = LegacyLayout{}
+
+// LegacyFS provides access to golden files stored in the layout of another
+// golden file library, allowing tests to switch to [Golden] without renaming
+// files. The other libraries store raw bytes which are best read using
+// [TextCodec].
+//
+// With [LegacyFS.Migrate] enabled golden files are moved to the names used by
+// [Golden] when updating, even if their content is unchanged. Per-test
+// directories of the layout are retained to keep the files of different tests
+// apart.
+type LegacyFS struct {
+	// Rename golden files to the names used by [Golden] when they're updated.
+	// Reads prefer migrated files. The filesystem must implement [RemoveFS].
+	Migrate bool
+
+	fs     fs.FS
+	prefix string
+	suffix string
+}
+
+var _ fs.FS = (*LegacyFS)(nil)
+var _ WriteFileFS = (*LegacyFS)(nil)
+var _ migratingFS = (*LegacyFS)(nil)
+
+// NewLegacyFS returns a filesystem for golden files stored in a directory
+// using the given layout. The test name is used for layouts with per-test
+// directories.
+func NewLegacyFS(tb TB, dir string, layout LegacyLayout) *LegacyFS {
+	return newLegacyFS(newWritableDirFS(dir), testName(tb), layout)
+}
+
+func newLegacyFS(fsys fs.FS, test string, layout LegacyLayout) *LegacyFS {
+	var dirs []string
+
+	parts := strings.Split(test, "/")
+
+	if layout.TestNameForDir {
+		dirs = append(dirs, parts[0])
+	}
+
+	if layout.SubTestNameForDir && len(parts) > 1 {
+		dirs = append(dirs, parts[1])
+	}
+
+	return &LegacyFS{
+		fs:     fsys,
+		prefix: path.Join(dirs...),
+		suffix: layout.Suffix,
+	}
+}
+
+// migratedPath returns the path of a migrated golden file. It's placed in the
+// per-test directory of the layout, if any.
+func (f *LegacyFS) migratedPath(op, name string) (string, error) {
+	result := path.Join(f.prefix, name)
+
+	if !fs.ValidPath(result) {
+		return "", &fs.PathError{Op: op, Path: result, Err: fs.ErrInvalid}
+	}
+
+	return result, nil
+}
+
+// legacyPath returns the path of a golden file in the legacy layout.
+func (f *LegacyFS) legacyPath(op, name string) (string, error) {
+	unescaped, err := url.PathUnescape(name)
+	if err != nil {
+		return "", &fs.PathError{Op: op, Path: name, Err: err}
+	}
+
+	result := path.Join(f.prefix, unescaped+f.suffix)
+
+	if !fs.ValidPath(result) {
+		return "", &fs.PathError{Op: op, Path: result, Err: fs.ErrInvalid}
+	}
+
+	return result, nil
+}
+
+func (f *LegacyFS) Open(name string) (fs.File, error) {
+	if f.Migrate {
+		mp, err := f.migratedPath("open", name)
+		if err != nil {
+			return nil, err
+		}
+
+		if file, err := f.fs.Open(mp); !errors.Is(err, fs.ErrNotExist) {
+			return file, err
+		}
+	}
+
+	p, err := f.legacyPath("open", name)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.fs.Open(p)
+}
+
+func (f *LegacyFS) lockPath(name string) (string, error) {
+	if lp, ok := f.fs.(lockPather); ok {
+		return lp.lockPath(name)
+	}
+
+	return "", nil
+}
+
+func (f *LegacyFS) WriteFile(name string, data []byte, perm os.FileMode) error {
+	wffs, ok := f.fs.(WriteFileFS)
+	if !ok || wffs == nil {
+		return fmt.Errorf("%w: %#v", errUpdateNotSupported, f.fs)
+	}
+
+	p, err := f.legacyPath("write", name)
+	if err != nil {
+		return err
+	}
+
+	if !f.Migrate {
+		return wffs.WriteFile(p, data, perm)
+	}
+
+	mp, err := f.migratedPath("write", name)
+	if err != nil {
+		return err
+	}
+
+	if p == mp {
+		return wffs.WriteFile(p, data, perm)
+	}
+
+	rfs, ok := f.fs.(RemoveFS)
+	if !ok {
+		return fmt.Errorf("%w: migration requires removing files from %#v", errUpdateNotSupported, f.fs)
+	}
+
+	if err := wffs.WriteFile(mp, data, perm); err != nil {
+		return err
+	}
+
+	if err := rfs.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("removing migrated golden file: %w", err)
+	}
+
+	return nil
+}
+
+// migrate moves a golden file from the legacy to the new name. Returns
+// whether the file was moved.
+func (f *LegacyFS) migrate(name string) (bool, error) {
+	if !f.Migrate {
+		return false, nil
+	}
+
+	p, err := f.legacyPath("migrate", name)
+	if err != nil {
+		return false, err
+	}
+
+	mp, err := f.migratedPath("migrate", name)
+	if err != nil || p == mp {
+		return false, err
+	}
+
+	if _, err := fs.Stat(f.fs, mp); !errors.Is(err, fs.ErrNotExist) {
+		// Already migrated.
+		return false, err
+	}
+
+	data, err := fs.ReadFile(f.fs, p)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			err = nil
+		}
+
+		return false, err
+	}
+
+	return true, f.WriteFile(name, data, 0o644)
+}
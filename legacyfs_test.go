@@ -0,0 +1,313 @@
+package aurum
+
+import (
+	"io/fs"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/hansmi/aurum/internal/testutil"
+)
+
+func TestLegacyFSPath(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		layout LegacyLayout
+		test   string
+		golden string
+		want   string
+	}{
+		{
+			name:   "gotest.tools",
+			layout: GotestToolsLayout,
+			test:   "TestValue",
+			golden: "value.golden",
+			want:   "value.golden",
+		},
+		{
+			name:   "goldie",
+			layout: GoldieLayout,
+			test:   "TestValue/sub",
+			golden: "value",
+			want:   "value.golden",
+		},
+		{
+			name:   "nested name",
+			layout: GoldieLayout,
+			golden: "dir/value",
+			want:   "dir/value.golden",
+		},
+		{
+			name: "test name",
+			layout: LegacyLayout{
+				Suffix:         ".golden",
+				TestNameForDir: true,
+			},
+			test:   "TestValue/sub/more",
+			golden: "value",
+			want:   "TestValue/value.golden",
+		},
+		{
+			name: "subtest name",
+			layout: LegacyLayout{
+				Suffix:            ".golden",
+				SubTestNameForDir: true,
+			},
+			test:   "TestValue/sub/more",
+			golden: "value",
+			want:   "sub/value.golden",
+		},
+		{
+			name: "test and subtest name",
+			layout: LegacyLayout{
+				Suffix:            ".golden",
+				TestNameForDir:    true,
+				SubTestNameForDir: true,
+			},
+			test:   "TestValue/sub",
+			golden: "value",
+			want:   "TestValue/sub/value.golden",
+		},
+		{
+			name: "subtest name without subtest",
+			layout: LegacyLayout{
+				SubTestNameForDir: true,
+			},
+			test:   "TestValue",
+			golden: "value",
+			want:   "value",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m := NewMemFS(map[string][]byte{
+				tc.want: []byte("content"),
+			})
+
+			f := newLegacyFS(m, tc.test, tc.layout)
+
+			if got, err := fs.ReadFile(f, url.PathEscape(tc.golden)); err != nil {
+				t.Errorf("ReadFile() failed: %v", err)
+			} else if diff := cmp.Diff("content", string(got)); diff != "" {
+				t.Errorf("ReadFile() diff (-want +got):\n%s", diff)
+			}
+
+			if err := f.WriteFile(url.PathEscape(tc.golden), []byte("changed"), 0o644); err != nil {
+				t.Errorf("WriteFile() failed: %v", err)
+			}
+
+			want := []MemFSWrite{{Name: tc.want, Data: []byte("changed"), Mode: 0o644}}
+
+			if diff := cmp.Diff(want, m.Writes()); diff != "" {
+				t.Errorf("Writes diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLegacyFSMigrate(t *testing.T) {
+	m := NewMemFS(map[string][]byte{
+		"legacy.golden":   []byte("legacy"),
+		"migrated":        []byte("new"),
+		"migrated.golden": []byte("stale"),
+	})
+
+	f := newLegacyFS(m, "", GoldieLayout)
+	f.Migrate = true
+
+	for _, tc := range []struct {
+		name string
+		want string
+	}{
+		{"legacy", "legacy"},
+		{"migrated", "new"},
+	} {
+		if got, err := fs.ReadFile(f, tc.name); err != nil {
+			t.Errorf("ReadFile(%q) failed: %v", tc.name, err)
+		} else if diff := cmp.Diff(tc.want, string(got)); diff != "" {
+			t.Errorf("ReadFile(%q) diff (-want +got):\n%s", tc.name, diff)
+		}
+	}
+
+	for _, name := range []string{"legacy", "migrated", "missing"} {
+		if _, err := f.migrate(name); err != nil {
+			t.Errorf("migrate(%q) failed: %v", name, err)
+		}
+	}
+
+	if err := f.WriteFile("migrated", []byte("changed"), 0o644); err != nil {
+		t.Errorf("WriteFile() failed: %v", err)
+	}
+
+	want := []MemFSWrite{
+		{Name: "legacy", Data: []byte("legacy"), Mode: 0o644},
+		{Name: "legacy.golden", Removed: true},
+		{Name: "migrated", Data: []byte("changed"), Mode: 0o644},
+		{Name: "migrated.golden", Removed: true},
+	}
+
+	if diff := cmp.Diff(want, m.Writes()); diff != "" {
+		t.Errorf("Writes diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestGoldenAssertLegacy(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		g       *globalOptions
+		migrate bool
+		value   string
+		want    []MemFSWrite
+	}{
+		{
+			name:  "read",
+			g:     &globalOptions{},
+			value: "content",
+		},
+		{
+			name:  "update",
+			g:     &globalOptions{updatesEnabled: true},
+			value: "changed",
+			want: []MemFSWrite{
+				{Name: "TestLegacy/value.golden", Data: []byte("changed"), Mode: 0o644},
+			},
+		},
+		{
+			name:    "migrate unchanged",
+			g:       &globalOptions{updatesEnabled: true},
+			migrate: true,
+			value:   "content",
+			want: []MemFSWrite{
+				{Name: "TestLegacy/value", Data: []byte("content"), Mode: 0o644},
+				{Name: "TestLegacy/value.golden", Removed: true},
+			},
+		},
+		{
+			name:    "migrate changed",
+			g:       &globalOptions{updatesEnabled: true},
+			migrate: true,
+			value:   "changed",
+			want: []MemFSWrite{
+				{Name: "TestLegacy/value", Data: []byte("changed"), Mode: 0o644},
+				{Name: "TestLegacy/value.golden", Removed: true},
+			},
+		},
+		{
+			name:    "migrate disabled",
+			g:       &globalOptions{},
+			migrate: true,
+			value:   "content",
+		},
+		{
+			name:    "migrate dry-run",
+			g:       &globalOptions{updatesEnabled: true, dryRun: true},
+			migrate: true,
+			value:   "content",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m := NewMemFS(map[string][]byte{
+				"TestLegacy/value.golden": []byte("content"),
+			})
+
+			f := newLegacyFS(m, "TestLegacy/case", LegacyLayout{
+				Suffix:         ".golden",
+				TestNameForDir: true,
+			})
+			f.Migrate = tc.migrate
+
+			o := &Golden{
+				g:     tc.g,
+				FS:    f,
+				Codec: &TextCodec{},
+			}
+
+			if err := o.assert("value", tc.value, t.Logf); err != nil {
+				t.Errorf("assert() failed: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.want, m.Writes(), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("Writes diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNewLegacyFS(t *testing.T) {
+	tmpdir := t.TempDir()
+
+	f := NewLegacyFS(&fakeTB{name: "TestExample/sub"}, tmpdir, LegacyLayout{
+		Suffix:            ".golden",
+		SubTestNameForDir: true,
+	})
+
+	if err := f.WriteFile("value", []byte("content"), 0o644); err != nil {
+		t.Errorf("WriteFile() failed: %v", err)
+	}
+
+	testutil.MustLstat(t, filepath.Join(tmpdir, "sub", "value.golden"))
+
+	f.Migrate = true
+
+	if moved, err := f.migrate("value"); err != nil {
+		t.Errorf("migrate() failed: %v", err)
+	} else if !moved {
+		t.Errorf("migrate() didn't move file")
+	}
+
+	testutil.MustLstat(t, filepath.Join(tmpdir, "sub", "value"))
+	testutil.MustNotExist(t, filepath.Join(tmpdir, "sub", "value.golden"))
+}
+
+func TestLegacyFSMigratePerTestDirs(t *testing.T) {
+	m := NewMemFS(map[string][]byte{
+		"TestA/output.golden": []byte("a"),
+		"TestB/output.golden": []byte("b"),
+	})
+
+	layout := LegacyLayout{
+		Suffix:         ".golden",
+		TestNameForDir: true,
+	}
+
+	for _, test := range []string{"TestA", "TestB"} {
+		o := &Golden{
+			g:     &globalOptions{updatesEnabled: true},
+			FS:    newLegacyFS(m, test, layout),
+			Codec: &TextCodec{},
+		}
+
+		o.FS.(*LegacyFS).Migrate = true
+
+		want := strings.ToLower(strings.TrimPrefix(test, "Test"))
+
+		if err := o.assert("output", want, t.Logf); err != nil {
+			t.Errorf("assert() for %s failed: %v", test, err)
+		}
+	}
+
+	want := map[string]string{
+		"TestA/output": "a",
+		"TestB/output": "b",
+	}
+
+	got := map[string]string{}
+
+	if err := fs.WalkDir(m, ".", func(p string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			data, err := fs.ReadFile(m, p)
+			got[p] = string(data)
+			return err
+		}
+
+		return err
+	}); err != nil {
+		t.Errorf("WalkDir() failed: %v", err)
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Files diff (-want +got):\n%s", diff)
+	}
+}
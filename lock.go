@@ -0,0 +1,95 @@
+package aurum
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// lockPather is implemented by filesystems storing files at a location on
+// disk. The returned absolute path identifies the storage of the given file
+// for locking purposes. An empty path is returned if the storage location is
+// unknown.
+type lockPather interface {
+	lockPath(name string) (string, error)
+}
+
+// pathLocks provides mutual exclusion keyed by arbitrary comparable values.
+type pathLocks struct {
+	mu    sync.Mutex
+	locks map[any]*sync.Mutex
+}
+
+func (l *pathLocks) lock(key any) func() {
+	l.mu.Lock()
+
+	m, ok := l.locks[key]
+	if !ok {
+		if l.locks == nil {
+			l.locks = map[any]*sync.Mutex{}
+		}
+
+		m = &sync.Mutex{}
+		l.locks[key] = m
+	}
+
+	l.mu.Unlock()
+
+	m.Lock()
+
+	return m.Unlock
+}
+
+// lockForUpdate serializes updates of a golden file within the process and,
+// if enabled, across processes using an advisory lock on the directory
+// containing the file. The returned function releases the locks. Nothing is
+// locked when updates are disabled or in dry-run mode.
+func (o *Golden) lockForUpdate(filename string) (func(), error) {
+	if !o.g.checkUpdatesEnabled() || o.g.checkDryRunEnabled() {
+		return func() {}, nil
+	}
+
+	var key any
+	var path string
+
+	if lp, ok := o.FS.(lockPather); ok {
+		var err error
+
+		if path, err = lp.lockPath(filename); err != nil {
+			return nil, err
+		}
+	}
+
+	if path != "" {
+		key = path
+	} else if location, ok := goldenLocation(o); ok {
+		key = goldenKey{location, filename}
+	} else {
+		return func() {}, nil
+	}
+
+	unlock := o.g.locks.lock(key)
+
+	if path == "" || !o.g.checkFileLockingEnabled() {
+		return unlock, nil
+	}
+
+	unlockFile, err := lockDir(filepath.Dir(path))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			// Writing will fail with a better error message.
+			return unlock, nil
+		}
+
+		unlock()
+
+		return nil, fmt.Errorf("locking golden file directory: %w", err)
+	}
+
+	return func() {
+		unlockFile()
+		unlock()
+	}, nil
+}
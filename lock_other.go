@@ -0,0 +1,8 @@
+//go:build !unix
+
+package aurum
+
+// lockDir is a no-op on platforms without advisory file locking.
+func lockDir(dir string) (func(), error) {
+	return func() {}, nil
+}
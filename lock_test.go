@@ -0,0 +1,158 @@
+package aurum
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// assertBlocked verifies that a function blocks until release is called.
+func assertBlocked(t *testing.T, fn func(), release func()) {
+	t.Helper()
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		fn()
+	}()
+
+	select {
+	case <-done:
+		t.Fatalf("Function didn't block")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	release()
+
+	<-done
+}
+
+func TestPathLocks(t *testing.T) {
+	var l pathLocks
+
+	unlockA := l.lock("a")
+
+	// Other keys are independent.
+	l.lock("b")()
+
+	assertBlocked(t, func() { l.lock("a")() }, unlockA)
+}
+
+func TestGoldenLockForUpdate(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, tc := range []struct {
+		name    string
+		g       func() *globalOptions
+		fs      func() *Golden
+		wantKey any
+	}{
+		{
+			name: "updates disabled",
+			fs: func() *Golden {
+				return &Golden{Dir: dir}
+			},
+		},
+		{
+			name: "dry-run",
+			g:    func() *globalOptions { return &globalOptions{updatesEnabled: true, dryRun: true} },
+			fs: func() *Golden {
+				return &Golden{Dir: dir}
+			},
+		},
+		{
+			name: "directory",
+			g:    func() *globalOptions { return &globalOptions{updatesEnabled: true, fileLocking: true} },
+			fs: func() *Golden {
+				return &Golden{Dir: dir}
+			},
+			wantKey: filepath.Join(dir, "file"),
+		},
+		{
+			name: "overlay",
+			g:    func() *globalOptions { return &globalOptions{updatesEnabled: true} },
+			fs: func() *Golden {
+				return &Golden{FS: NewDirOverlayFS(t.TempDir(), dir)}
+			},
+			wantKey: filepath.Join(dir, "file"),
+		},
+		{
+			name: "txtar",
+			g:    func() *globalOptions { return &globalOptions{updatesEnabled: true, fileLocking: true} },
+			fs: func() *Golden {
+				return &Golden{FS: NewTxtarFS(filepath.Join(dir, "archive.txtar"))}
+			},
+			wantKey: filepath.Join(dir, "archive.txtar"),
+		},
+		{
+			name: "missing directory",
+			g:    func() *globalOptions { return &globalOptions{updatesEnabled: true, fileLocking: true} },
+			fs: func() *Golden {
+				return &Golden{Dir: filepath.Join(dir, "missing")}
+			},
+			wantKey: filepath.Join(dir, "missing", "file"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := &globalOptions{}
+
+			if tc.g != nil {
+				g = tc.g()
+			}
+
+			o := tc.fs()
+			o.g = g
+			o.applyDefaults()
+
+			unlock, err := o.lockForUpdate("file")
+			if err != nil {
+				t.Fatalf("lockForUpdate() failed: %v", err)
+			}
+
+			var keys []any
+
+			g.locks.mu.Lock()
+			for key := range g.locks.locks {
+				keys = append(keys, key)
+			}
+			g.locks.mu.Unlock()
+
+			if tc.wantKey == nil {
+				if len(keys) != 0 {
+					t.Errorf("Unexpected locks: %v", keys)
+				}
+
+				unlock()
+			} else {
+				if diff := cmp.Diff([]any{tc.wantKey}, keys); diff != "" {
+					t.Errorf("Lock keys diff (-want +got):\n%s", diff)
+				}
+
+				assertBlocked(t, func() { g.locks.lock(tc.wantKey)() }, unlock)
+			}
+		})
+	}
+}
+
+func TestGoldenLockForUpdateMemFS(t *testing.T) {
+	g := &globalOptions{updatesEnabled: true}
+	o := &Golden{g: g, FS: &MemFS{}}
+
+	unlock, err := o.lockForUpdate("file")
+	if err != nil {
+		t.Fatalf("lockForUpdate() failed: %v", err)
+	}
+
+	other := &Golden{g: g, FS: o.FS}
+
+	assertBlocked(t, func() {
+		if unlock, err := other.lockForUpdate("file"); err != nil {
+			t.Errorf("lockForUpdate() failed: %v", err)
+		} else {
+			unlock()
+		}
+	}, unlock)
+}
@@ -0,0 +1,35 @@
+//go:build unix
+
+package aurum
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockDir acquires an exclusive advisory lock on a directory, waiting until
+// it becomes available.
+func lockDir(dir string) (func(), error) {
+	fh, err := os.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		err = syscall.Flock(int(fh.Fd()), syscall.LOCK_EX)
+		if err != syscall.EINTR {
+			break
+		}
+	}
+
+	if err != nil {
+		fh.Close()
+
+		return nil, &os.PathError{Op: "flock", Path: dir, Err: err}
+	}
+
+	return func() {
+		// Closing the file releases the lock.
+		fh.Close()
+	}, nil
+}
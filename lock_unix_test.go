@@ -0,0 +1,24 @@
+//go:build unix
+
+package aurum
+
+import "testing"
+
+func TestLockDir(t *testing.T) {
+	dir := t.TempDir()
+
+	unlock, err := lockDir(dir)
+	if err != nil {
+		t.Fatalf("lockDir() failed: %v", err)
+	}
+
+	// Locks on separate file descriptors exclude each other even within the
+	// same process.
+	assertBlocked(t, func() {
+		if unlock, err := lockDir(dir); err != nil {
+			t.Errorf("lockDir() failed: %v", err)
+		} else {
+			unlock()
+		}
+	}, unlock)
+}
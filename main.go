@@ -0,0 +1,66 @@
+package aurum
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// M is the subset of [testing.M] used by [Main].
+type M interface {
+	Run() int
+}
+
+// Main runs the tests and prints a summary of the outcomes of all assertions
+// made using [Golden.Assert] and [Golden.Snapshot]. In dry-run update mode the
+// changes which would be made to golden files are listed as well. The results
+// can also be written to a JSON file (see [WithSummaryJSON]). Reporters
+// implementing [io.Closer] are closed (see [WithReporter]). Use it from
+// TestMain:
+//
+//	func TestMain(m *testing.M) {
+//	  aurum.Main(m)
+//	}
+//
+// Main calls [os.Exit] with the result of running the tests.
+func Main(m M) {
+	os.Exit(global.runMain(m, os.Stdout))
+}
+
+func (g *globalOptions) runMain(m M, w io.Writer) int {
+	code := m.Run()
+
+	if err := g.results.writeSummary(w); err != nil && code == 0 {
+		code = 1
+	}
+
+	g.mu.Lock()
+	summaryJSON := g.summaryJSON
+	g.mu.Unlock()
+
+	if summaryJSON != "" {
+		if err := g.results.writeJSON(summaryJSON); err != nil {
+			fmt.Fprintf(w, "Writing golden assertion summary failed: %v\n", err)
+
+			if code == 0 {
+				code = 1
+			}
+		}
+	}
+
+	if err := g.reporters.close(); err != nil {
+		fmt.Fprintf(w, "Closing golden assertion reporter failed: %v\n", err)
+
+		if code == 0 {
+			code = 1
+		}
+	}
+
+	if g.checkDryRunEnabled() {
+		if err := g.plan.writeSummary(w); err != nil && code == 0 {
+			code = 1
+		}
+	}
+
+	return code
+}
@@ -0,0 +1,125 @@
+package aurum
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"sync"
+	"testing/fstest"
+)
+
+// RemoveFS is implemented by filesystems supporting the removal of files.
+type RemoveFS interface {
+	Remove(name string) error
+}
+
+// MemFSWrite describes a modification of a [MemFS].
+type MemFSWrite struct {
+	Name string
+	Data []byte
+	Mode fs.FileMode
+
+	// Whether the file was removed.
+	Removed bool
+}
+
+// MemFS is an in-memory filesystem recording all modifications. It's useful
+// for testing code built on top of [Golden] and as a dry-run backend to
+// determine which golden files an update would touch.
+//
+// The zero value is an empty filesystem. All methods are safe for concurrent
+// use.
+type MemFS struct {
+	mu     sync.Mutex
+	files  fstest.MapFS
+	writes []MemFSWrite
+}
+
+var _ fs.FS = (*MemFS)(nil)
+var _ WriteFileFS = (*MemFS)(nil)
+var _ RemoveFS = (*MemFS)(nil)
+
+// NewMemFS returns a filesystem with the given initial content. The initial
+// files are not recorded as writes.
+func NewMemFS(files map[string][]byte) *MemFS {
+	m := &MemFS{
+		files: fstest.MapFS{},
+	}
+
+	for name, data := range files {
+		m.files[name] = &fstest.MapFile{
+			Data: bytes.Clone(data),
+			Mode: 0o644,
+		}
+	}
+
+	return m
+}
+
+func (m *MemFS) Open(name string) (fs.File, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Files are replaced on writes, never modified. Open files retain their
+	// content.
+	return m.files.Open(name)
+}
+
+func (m *MemFS) WriteFile(name string, data []byte, perm os.FileMode) error {
+	if !fs.ValidPath(name) || name == "." {
+		return &fs.PathError{Op: "write", Path: name, Err: fs.ErrInvalid}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.files == nil {
+		m.files = fstest.MapFS{}
+	}
+
+	m.files[name] = &fstest.MapFile{
+		Data: bytes.Clone(data),
+		Mode: perm,
+	}
+
+	m.writes = append(m.writes, MemFSWrite{
+		Name: name,
+		Data: bytes.Clone(data),
+		Mode: perm,
+	})
+
+	return nil
+}
+
+func (m *MemFS) Remove(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.files[name]; !ok {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+
+	delete(m.files, name)
+
+	m.writes = append(m.writes, MemFSWrite{
+		Name:    name,
+		Removed: true,
+	})
+
+	return nil
+}
+
+// Writes returns all modifications in the order they were made.
+func (m *MemFS) Writes() []MemFSWrite {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := make([]MemFSWrite, len(m.writes))
+
+	for idx, w := range m.writes {
+		w.Data = bytes.Clone(w.Data)
+		result[idx] = w
+	}
+
+	return result
+}
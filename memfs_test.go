@@ -0,0 +1,108 @@
+package aurum
+
+import (
+	"io/fs"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMemFS(t *testing.T) {
+	m := NewMemFS(map[string][]byte{
+		"initial":     []byte("content"),
+		"dir/example": nil,
+	})
+
+	if err := fstest.TestFS(m, "initial", "dir/example"); err != nil {
+		t.Error(err)
+	}
+
+	if err := m.WriteFile("new", []byte("data"), 0o600); err != nil {
+		t.Errorf("WriteFile() failed: %v", err)
+	}
+
+	if err := m.WriteFile("initial", []byte("changed"), 0o644); err != nil {
+		t.Errorf("WriteFile() failed: %v", err)
+	}
+
+	if err := m.Remove("dir/example"); err != nil {
+		t.Errorf("Remove() failed: %v", err)
+	}
+
+	if err := m.Remove("missing"); !os.IsNotExist(err) {
+		t.Errorf("Remove() returned %v, want %v", err, os.ErrNotExist)
+	}
+
+	if err := m.WriteFile("/invalid", nil, 0o644); err == nil {
+		t.Errorf("WriteFile() with invalid path succeeded")
+	}
+
+	if diff := cmp.Diff([]MemFSWrite{
+		{Name: "new", Data: []byte("data"), Mode: 0o600},
+		{Name: "initial", Data: []byte("changed"), Mode: 0o644},
+		{Name: "dir/example", Removed: true},
+	}, m.Writes()); diff != "" {
+		t.Errorf("Writes() diff (-want +got):\n%s", diff)
+	}
+
+	if err := fstest.TestFS(m, "initial", "new"); err != nil {
+		t.Error(err)
+	}
+
+	if got, err := fs.ReadFile(m, "initial"); err != nil {
+		t.Errorf("ReadFile() failed: %v", err)
+	} else if diff := cmp.Diff("changed", string(got)); diff != "" {
+		t.Errorf("ReadFile() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestMemFSZero(t *testing.T) {
+	var m MemFS
+
+	if _, err := fs.ReadFile(&m, "file"); !os.IsNotExist(err) {
+		t.Errorf("ReadFile() returned %v, want %v", err, os.ErrNotExist)
+	}
+
+	if err := m.WriteFile("file", nil, 0o644); err != nil {
+		t.Errorf("WriteFile() failed: %v", err)
+	}
+
+	if err := fstest.TestFS(&m, "file"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGoldenAssertMemFS(t *testing.T) {
+	m := NewMemFS(map[string][]byte{
+		"unchanged": []byte(`"value"`),
+		"changed":   []byte(`"value"`),
+	})
+
+	o := &Golden{
+		g: &globalOptions{
+			updatesEnabled: true,
+		},
+		FS: m,
+	}
+
+	for _, name := range []string{"unchanged", "changed", "created"} {
+		value := "value"
+
+		if name != "unchanged" {
+			value = name
+		}
+
+		if err := o.assert(name, value, t.Logf); err != nil {
+			t.Errorf("assert(%q) failed: %v", name, err)
+		}
+	}
+
+	if diff := cmp.Diff([]MemFSWrite{
+		{Name: "changed", Data: []byte("\"changed\"\n"), Mode: 0o644},
+		{Name: "created", Data: []byte("\"created\"\n"), Mode: 0o644},
+	}, m.Writes()); diff != "" {
+		t.Errorf("Writes() diff (-want +got):\n%s", diff)
+	}
+}
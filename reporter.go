@@ -0,0 +1,67 @@
+package aurum
+
+import (
+	"io"
+	"sync"
+)
+
+// Event is implemented by all values passed to a [Reporter]. The set of
+// events may be extended in the future.
+type Event interface {
+	isEvent()
+}
+
+func (AssertionResult) isEvent() {}
+
+// Reporter receives events about golden file assertions, e.g. to produce
+// machine-readable output for CI systems or metrics. Events include
+// [GoldenReadEvent], [ComparisonEvent], [UnmarshalFailedEvent],
+// [UpdateWrittenEvent], [UpdateSkippedEvent] and finally [AssertionResult].
+// Reporters are configured globally using [WithReporter] or per instance via
+// [Golden.Reporter] and must be safe for concurrent use. Reporters implementing
+// [io.Closer] are closed by [Main] once all tests have finished.
+type Reporter interface {
+	Report(Event)
+}
+
+// reporterList dispatches events to multiple reporters.
+type reporterList struct {
+	mu        sync.Mutex
+	reporters []Reporter
+}
+
+func (l *reporterList) add(r Reporter) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.reporters = append(l.reporters, r)
+}
+
+func (l *reporterList) all() []Reporter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]Reporter(nil), l.reporters...)
+}
+
+func (l *reporterList) report(e Event) {
+	for _, r := range l.all() {
+		r.Report(e)
+	}
+}
+
+// close closes all reporters implementing [io.Closer] and returns the first
+// error.
+func (l *reporterList) close() error {
+	var firstErr error
+
+	for _, r := range l.all() {
+		if c, ok := r.(io.Closer); ok {
+			if err := c.Close(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	return firstErr
+}
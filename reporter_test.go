@@ -0,0 +1,80 @@
+package aurum
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type fakeReporter struct {
+	events   []Event
+	closed   bool
+	closeErr error
+}
+
+func (r *fakeReporter) Report(e Event) {
+	r.events = append(r.events, e)
+}
+
+func (r *fakeReporter) Close() error {
+	r.closed = true
+
+	return r.closeErr
+}
+
+func TestWithReporter(t *testing.T) {
+	r := &fakeReporter{}
+
+	g := &globalOptions{}
+
+	WithReporter(r).apply(g)
+
+	o := &Golden{
+		g: g,
+		FS: NewMemFS(map[string][]byte{
+			"file": []byte("\"value\"\n"),
+		}),
+	}
+
+	code := g.runMain(&fakeM{
+		run: func() {
+			o.Assert(&fakeTB{name: "TestReporter"}, "file", "value")
+		},
+	}, &strings.Builder{})
+
+	if code != 0 {
+		t.Errorf("runMain() returned %d, want 0", code)
+	}
+
+	want := []Event{
+		GoldenReadEvent{Test: "TestReporter", Path: "file", Size: 8},
+		ComparisonEvent{Test: "TestReporter", Path: "file", Equal: true},
+		AssertionResult{Test: "TestReporter", Path: "file", Outcome: OutcomePassed},
+	}
+
+	if diff := cmp.Diff(want, r.events); diff != "" {
+		t.Errorf("Events diff (-want +got):\n%s", diff)
+	}
+
+	if !r.closed {
+		t.Errorf("Reporter wasn't closed")
+	}
+}
+
+func TestMainReporterCloseError(t *testing.T) {
+	g := &globalOptions{}
+
+	WithReporter(&fakeReporter{closeErr: errors.New("test")}).apply(g)
+
+	var buf strings.Builder
+
+	if code := g.runMain(&fakeM{}, &buf); code != 1 {
+		t.Errorf("runMain() returned %d, want 1", code)
+	}
+
+	if !strings.Contains(buf.String(), "reporter failed: test") {
+		t.Errorf("Missing error message: %q", buf.String())
+	}
+}
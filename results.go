@@ -0,0 +1,154 @@
+package aurum
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"text/tabwriter"
+)
+
+// Outcome describes the result of a golden file assertion.
+type Outcome int
+
+const (
+	// The value matched the golden file.
+	OutcomePassed Outcome = iota
+
+	// The assertion failed, e.g. because of a difference or an error.
+	OutcomeFailed
+
+	// A missing golden file was created.
+	OutcomeCreated
+
+	// An existing golden file was rewritten.
+	OutcomeUpdated
+
+	// The value matched the golden file, but the file content differs from
+	// the current encoding of the value. The file isn't rewritten.
+	OutcomeReformatted
+)
+
+var outcomeNames = [...]string{
+	OutcomePassed:      "passed",
+	OutcomeFailed:      "failed",
+	OutcomeCreated:     "created",
+	OutcomeUpdated:     "updated",
+	OutcomeReformatted: "reformatted",
+}
+
+func (o Outcome) String() string {
+	if o >= 0 && int(o) < len(outcomeNames) {
+		return outcomeNames[o]
+	}
+
+	return fmt.Sprintf("Outcome(%d)", int(o))
+}
+
+func (o Outcome) MarshalText() ([]byte, error) {
+	return []byte(o.String()), nil
+}
+
+// AssertionResult describes the outcome of a single golden file assertion.
+type AssertionResult struct {
+	// Name of the test making the assertion. Empty if unknown.
+	Test string `json:"test"`
+
+	// Path of the golden file.
+	Path string `json:"path"`
+
+	Outcome Outcome `json:"outcome"`
+
+	// Error message for failed assertions.
+	Message string `json:"message,omitempty"`
+}
+
+// resultCollector gathers the results of all assertions in a process.
+type resultCollector struct {
+	mu      sync.Mutex
+	results []AssertionResult
+}
+
+func (c *resultCollector) record(r AssertionResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.results = append(c.results, r)
+}
+
+// sorted returns a copy of all results ordered by path and test name.
+func (c *resultCollector) sorted() []AssertionResult {
+	c.mu.Lock()
+	results := append([]AssertionResult(nil), c.results...)
+	c.mu.Unlock()
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Path != results[j].Path {
+			return results[i].Path < results[j].Path
+		}
+
+		return results[i].Test < results[j].Test
+	})
+
+	return results
+}
+
+// writeSummary writes a table of all assertions which didn't pass unchanged.
+// Nothing is written if no results were recorded.
+func (c *resultCollector) writeSummary(w io.Writer) error {
+	results := c.sorted()
+
+	if len(results) == 0 {
+		return nil
+	}
+
+	counts := map[Outcome]int{}
+
+	for _, r := range results {
+		counts[r.Outcome]++
+	}
+
+	if _, err := fmt.Fprintf(w, "Golden file assertions: %d passed, %d failed, %d created, %d updated, %d reformatted\n",
+		counts[OutcomePassed], counts[OutcomeFailed], counts[OutcomeCreated],
+		counts[OutcomeUpdated], counts[OutcomeReformatted]); err != nil {
+		return err
+	}
+
+	if counts[OutcomePassed] == len(results) {
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	for _, r := range results {
+		if r.Outcome == OutcomePassed {
+			continue
+		}
+
+		if _, err := fmt.Fprintf(tw, "  %s\t%s\t%s\n", r.Outcome, r.Path, r.Test); err != nil {
+			return err
+		}
+	}
+
+	return tw.Flush()
+}
+
+// writeJSON stores all results in a JSON file.
+func (c *resultCollector) writeJSON(path string) error {
+	results := c.sorted()
+
+	if results == nil {
+		results = []AssertionResult{}
+	}
+
+	data, err := json.MarshalIndent(struct {
+		Results []AssertionResult `json:"results"`
+	}{results}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
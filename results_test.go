@@ -0,0 +1,202 @@
+package aurum
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hansmi/aurum/internal/ref"
+)
+
+func TestGoldenAssertOutcome(t *testing.T) {
+	for _, tc := range []struct {
+		name           string
+		content        *string
+		value          string
+		updatesEnabled bool
+		want           Outcome
+		wantErr        bool
+	}{
+		{
+			name:    "passed",
+			content: ref.Ref("\"value\"\n"),
+			value:   "value",
+			want:    OutcomePassed,
+		},
+		{
+			name:    "reformatted",
+			content: ref.Ref("  \"value\"  \n"),
+			value:   "value",
+			want:    OutcomeReformatted,
+		},
+		{
+			name:    "different",
+			content: ref.Ref("\"value\"\n"),
+			value:   "changed",
+			want:    OutcomeFailed,
+			wantErr: true,
+		},
+		{
+			name:    "missing",
+			value:   "value",
+			want:    OutcomeFailed,
+			wantErr: true,
+		},
+		{
+			name:           "created",
+			value:          "value",
+			updatesEnabled: true,
+			want:           OutcomeCreated,
+		},
+		{
+			name:           "updated",
+			content:        ref.Ref("\"value\"\n"),
+			value:          "changed",
+			updatesEnabled: true,
+			want:           OutcomeUpdated,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			files := map[string][]byte{}
+
+			if tc.content != nil {
+				files["file"] = []byte(*tc.content)
+			}
+
+			o := &Golden{
+				g:  &globalOptions{updatesEnabled: tc.updatesEnabled},
+				FS: NewMemFS(files),
+			}
+
+			got, err := o.assertOutcome("file", tc.value, t.Logf)
+
+			if (err != nil) != tc.wantErr {
+				t.Errorf("assertOutcome() returned %v, want error %t", err, tc.wantErr)
+			}
+
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Outcome diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestResultCollector(t *testing.T) {
+	var c resultCollector
+
+	var buf strings.Builder
+
+	if err := c.writeSummary(&buf); err != nil {
+		t.Errorf("writeSummary() failed: %v", err)
+	} else if buf.Len() != 0 {
+		t.Errorf("writeSummary() without results wrote %q", buf.String())
+	}
+
+	c.record(AssertionResult{Test: "TestB", Path: "testdata/b", Outcome: OutcomeUpdated})
+	c.record(AssertionResult{Test: "TestA", Path: "testdata/a", Outcome: OutcomePassed})
+	c.record(AssertionResult{Test: "TestA/sub", Path: "testdata/a/c", Outcome: OutcomeFailed, Message: "values differ"})
+	c.record(AssertionResult{Test: "TestC", Path: "testdata/c", Outcome: OutcomeReformatted})
+
+	buf.Reset()
+
+	if err := c.writeSummary(&buf); err != nil {
+		t.Errorf("writeSummary() failed: %v", err)
+	}
+
+	want := `Golden file assertions: 1 passed, 1 failed, 0 created, 1 updated, 1 reformatted
+  failed       testdata/a/c  TestA/sub
+  updated      testdata/b    TestB
+  reformatted  testdata/c    TestC
+`
+
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("Summary diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestMainSummaryJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary.json")
+
+	g := &globalOptions{
+		summaryJSON: path,
+	}
+
+	o := &Golden{
+		g: g,
+		FS: NewMemFS(map[string][]byte{
+			"first": []byte("\"value\"\n"),
+		}),
+	}
+
+	var buf strings.Builder
+
+	code := g.runMain(&fakeM{
+		run: func() {
+			tb := &fakeTB{name: "TestFirst"}
+			o.Assert(tb, "first", "value")
+			o.Assert(tb, "second", "value")
+		},
+	}, &buf)
+
+	if code != 0 {
+		t.Errorf("runMain() returned %d, want 0", code)
+	}
+
+	if !strings.HasPrefix(buf.String(), "Golden file assertions: 1 passed, 1 failed,") {
+		t.Errorf("Unexpected summary: %q", buf.String())
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() failed: %v", err)
+	}
+
+	want := `{
+  "results": [
+    {
+      "test": "TestFirst",
+      "path": "first",
+      "outcome": "passed"
+    },
+    {
+      "test": "TestFirst",
+      "path": "second",
+      "outcome": "failed",
+      "message": "golden file is missing; open second: file does not exist"
+    }
+  ]
+}
+`
+
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("JSON diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestMainSummaryJSONError(t *testing.T) {
+	g := &globalOptions{
+		summaryJSON: filepath.Join(t.TempDir(), "missing", "summary.json"),
+	}
+
+	var buf strings.Builder
+
+	if code := g.runMain(&fakeM{}, &buf); code != 1 {
+		t.Errorf("runMain() returned %d, want 1", code)
+	}
+
+	if !strings.Contains(buf.String(), "summary failed") {
+		t.Errorf("Missing error message: %q", buf.String())
+	}
+}
+
+func TestOutcomeString(t *testing.T) {
+	if got := Outcome(100).String(); got != "Outcome(100)" {
+		t.Errorf("String() returned %q", got)
+	}
+
+	if _, err := OutcomeCreated.MarshalText(); err != nil {
+		t.Errorf("MarshalText() failed: %v", err)
+	}
+}
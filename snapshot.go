@@ -0,0 +1,445 @@
+package aurum
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/hansmi/aurum/internal/codecutil"
+	"github.com/hansmi/aurum/internal/txtar"
+	"go.uber.org/multierr"
+)
+
+var errSnapshotEntryMissing = errors.New("snapshot entry is missing")
+var errSnapshotEntryUnused = errors.New("snapshot entry was not asserted")
+var errSnapshotNestedTxtar = errors.New("snapshots stored as txtar archive can't be written to a TxtarFS, use the JSON codec")
+var errSnapshotTestFailed = errors.New("snapshot not updated because the test failed")
+
+// SnapshotTB is the subset of [testing.TB] used for snapshots.
+type SnapshotTB interface {
+	TB
+	Name() string
+	Cleanup(func())
+	Failed() bool
+}
+
+type snapshotEntry struct {
+	name      string
+	value     any
+	valueType reflect.Type
+}
+
+type snapshotMember struct {
+	name string
+	data []byte
+}
+
+// snapshotFormat implements the storage of multiple named values in a single
+// file.
+type snapshotFormat interface {
+	decode([]byte) ([]snapshotMember, error)
+	encode([]snapshotMember) ([]byte, error)
+}
+
+// jsonSnapshotFormat stores values as members of a JSON object.
+type jsonSnapshotFormat struct{}
+
+func (jsonSnapshotFormat) decode(data []byte) ([]snapshotMember, error) {
+	var result []snapshotMember
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	if tok, err := dec.Token(); err != nil {
+		return nil, err
+	} else if tok != json.Delim('{') {
+		return nil, fmt.Errorf("snapshot must be a JSON object, got %v", tok)
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		var raw json.RawMessage
+
+		if err := dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+
+		result = append(result, snapshotMember{
+			name: tok.(string),
+			data: raw,
+		})
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (jsonSnapshotFormat) encode(members []snapshotMember) ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteByte('{')
+
+	for idx, m := range members {
+		if idx > 0 {
+			buf.WriteByte(',')
+		}
+
+		name, err := json.Marshal(m.name)
+		if err != nil {
+			return nil, err
+		}
+
+		buf.WriteString("\n  ")
+		buf.Write(name)
+		buf.WriteString(": ")
+
+		if err := json.Indent(&buf, bytes.TrimSpace(m.data), "  ", "  "); err != nil {
+			return nil, fmt.Errorf("snapshot entry %q: %w", m.name, err)
+		}
+	}
+
+	if len(members) > 0 {
+		buf.WriteByte('\n')
+	}
+
+	buf.WriteString("}\n")
+
+	return buf.Bytes(), nil
+}
+
+// txtarSnapshotFormat stores values as files in a txtar archive.
+type txtarSnapshotFormat struct{}
+
+func (txtarSnapshotFormat) decode(data []byte) ([]snapshotMember, error) {
+	var result []snapshotMember
+
+	for _, f := range txtar.Parse(data).Files {
+		result = append(result, snapshotMember{
+			name: f.Name,
+			data: f.Data,
+		})
+	}
+
+	return result, nil
+}
+
+func (txtarSnapshotFormat) encode(members []snapshotMember) ([]byte, error) {
+	var a txtar.Archive
+
+	for _, m := range members {
+		a.Files = append(a.Files, txtar.File{
+			Name: m.name,
+			Data: m.data,
+		})
+	}
+
+	return txtar.Format(&a)
+}
+
+// snapshotFormatFor returns a JSON object format for the JSON codec and
+// a txtar archive otherwise.
+func snapshotFormatFor(c Codec) snapshotFormat {
+	if hc, ok := c.(*headerCodec); ok {
+		c = hc.inner
+	}
+
+	if _, ok := c.(*JSONCodec); ok {
+		return jsonSnapshotFormat{}
+	}
+
+	return txtarSnapshotFormat{}
+}
+
+// Snapshot accumulates named values during a test. Once the test and all its
+// subtests have finished all values are stored in a single golden file named
+// after the test. Create instances using [Golden.Snapshot].
+//
+// Values stored with the JSON codec are written as members of a JSON object.
+// All other codecs use a txtar archive with one file per value (see
+// [golang.org/x/tools/txtar]). Values containing lines of the form
+// "-- NAME --" can't be stored in a txtar archive. Such archives can't be
+// nested in a [TxtarFS].
+//
+// Each entry is compared individually. When updating only changed entries are
+// rewritten and entries no longer added are removed. Snapshots of failed tests
+// are not updated. Entries not added are kept if subtests may have been
+// excluded via "-run" or "-skip", e.g. "-run TestTable/first".
+type Snapshot struct {
+	golden Golden
+	tb     SnapshotTB
+
+	mu      sync.Mutex
+	entries []snapshotEntry
+	names   map[string]struct{}
+}
+
+// Snapshot returns a new snapshot for the given test. The golden file is
+// checked, and if enabled updated, during the cleanup phase of the test.
+func (o *Golden) Snapshot(tb SnapshotTB) *Snapshot {
+	s := &Snapshot{
+		golden: o.callerRelative(1),
+		tb:     tb,
+		names:  map[string]struct{}{},
+	}
+
+	tb.Cleanup(s.finish)
+
+	return s
+}
+
+// Add records a value. Names must be unique within a snapshot, must not be
+// empty and must neither contain line breaks nor leading or trailing
+// whitespace.
+func (s *Snapshot) Add(name string, value any) {
+	s.tb.Helper()
+
+	if name == "" || strings.TrimSpace(name) != name || strings.ContainsAny(name, "\r\n") {
+		s.tb.Errorf("Invalid snapshot entry name %q", name)
+		return
+	}
+
+	if err := codecutil.CheckValueType(value); err != nil {
+		s.tb.Errorf("Snapshot entry %q: %v", name, err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.names[name]; ok {
+		s.tb.Errorf("Duplicate snapshot entry %q", name)
+		return
+	}
+
+	value, valueType := codecutil.NormalizeValue(value)
+
+	s.names[name] = struct{}{}
+	s.entries = append(s.entries, snapshotEntry{
+		name:      name,
+		value:     value,
+		valueType: valueType,
+	})
+}
+
+func (s *Snapshot) finish() {
+	s.tb.Helper()
+
+	s.mu.Lock()
+	entries := s.entries
+	s.mu.Unlock()
+
+	s.golden.applyDefaults()
+
+	name := s.tb.Name()
+	s.golden.test = name
+
+	// Entries missing from a failed test, e.g. after t.Fatal, are kept.
+	failed := s.tb.Failed()
+
+	if failed && s.golden.g.checkUpdatesEnabled() {
+		s.golden.recordResult(name, OutcomeFailed, errSnapshotTestFailed)
+		s.tb.Logf("%v", errSnapshotTestFailed)
+		return
+	}
+
+	partial := failed || s.golden.g.checkSubtestFilter()
+
+	outcome, err := s.golden.assertSnapshotOutcome(name, entries, partial, s.tb.Logf)
+
+	s.golden.recordResult(name, outcome, err)
+
+	for _, err := range multierr.Errors(err) {
+		s.tb.Errorf("%s", s.golden.errorText(err))
+	}
+}
+
+func (o Golden) assertSnapshot(name string, entries []snapshotEntry, logf logFunc) error {
+	_, err := o.assertSnapshotOutcome(name, entries, false, logf)
+
+	return err
+}
+
+// assertSnapshotOutcome implements [Golden.assertSnapshot] and additionally
+// returns the outcome. Errors always have [OutcomeFailed]. For partial runs
+// the stored entries not in the given list are retained.
+func (o Golden) assertSnapshotOutcome(name string, entries []snapshotEntry, partial bool, logf logFunc) (Outcome, error) {
+	o.applyDefaults()
+	o.selectCodec(name)
+
+	format := snapshotFormatFor(o.Codec)
+
+	if _, ok := format.(txtarSnapshotFormat); ok {
+		if _, ok := o.FS.(*TxtarFS); ok {
+			return OutcomeFailed, errSnapshotNestedTxtar
+		}
+	}
+
+	filename := url.PathEscape(name)
+	updatesEnabled := o.g.checkUpdatesEnabled()
+
+	var members []snapshotMember
+
+	unlock, err := o.lockForUpdate(filename)
+	if err != nil {
+		return OutcomeFailed, err
+	}
+
+	defer unlock()
+
+	previous, err := o.readGolden(filename)
+	if err == nil {
+		if members, err = format.decode(previous); err != nil {
+			o.reportUnmarshalFailed(filename, err)
+
+			err = multierr.Append(errGoldenUnmarshalFailed, err)
+		}
+	}
+
+	if err != nil {
+		if !(errors.Is(err, errGoldenMissing) || errors.Is(err, errGoldenUnmarshalFailed)) {
+			return OutcomeFailed, err
+		}
+
+		if !updatesEnabled {
+			o.reportSkipped(filename, updatesDisabledReason, previous, nil)
+
+			return OutcomeFailed, err
+		}
+
+		members = nil
+	}
+
+	index := map[string]int{}
+
+	for idx, m := range members {
+		index[m.name] = idx
+	}
+
+	var allErr error
+	var changed bool
+
+	// Description of all changes for approval in interactive mode.
+	var changes error
+
+	for _, e := range entries {
+		idx, found := index[e.name]
+
+		var template []byte
+
+		if found {
+			template = members[idx].data
+		}
+
+		valueBytes, err := o.verifiedMarshal(e.value, e.valueType, template)
+		if err != nil {
+			multierr.AppendInto(&allErr, fmt.Errorf("snapshot entry %q: %w", e.name, err))
+			continue
+		}
+
+		var diffErr error
+
+		entryFilename := filename + "." + url.PathEscape(e.name)
+
+		if !found {
+			diffErr = errSnapshotEntryMissing
+		} else if want, err := o.unmarshalGolden(entryFilename, template, e.valueType); err != nil {
+			diffErr = err
+		} else {
+			diffErr = o.compareGolden(entryFilename, want, e.value, updatesEnabled)
+		}
+
+		if diffErr == nil {
+			continue
+		}
+
+		if !updatesEnabled {
+			o.reportSkipped(entryFilename, updatesDisabledReason, template, valueBytes)
+			multierr.AppendInto(&allErr, fmt.Errorf("snapshot entry %q: %w", e.name, diffErr))
+			continue
+		}
+
+		logf("Snapshot entry %q: %v", e.name, diffErr)
+		multierr.AppendInto(&changes, fmt.Errorf("snapshot entry %q: %w", e.name, diffErr))
+
+		if found {
+			members[idx].data = valueBytes
+		} else {
+			index[e.name] = len(members)
+			members = append(members, snapshotMember{
+				name: e.name,
+				data: valueBytes,
+			})
+		}
+
+		changed = true
+	}
+
+	asserted := map[string]struct{}{}
+
+	for _, e := range entries {
+		asserted[e.name] = struct{}{}
+	}
+
+	kept := members[:0]
+
+	for _, m := range members {
+		if _, ok := asserted[m.name]; ok || partial {
+			kept = append(kept, m)
+		} else if updatesEnabled {
+			logf("Removing snapshot entry %q.", m.name)
+			multierr.AppendInto(&changes, fmt.Errorf("snapshot entry %q: %w", m.name, errSnapshotEntryUnused))
+			changed = true
+		} else {
+			multierr.AppendInto(&allErr, fmt.Errorf("snapshot entry %q: %w", m.name, errSnapshotEntryUnused))
+		}
+	}
+
+	if allErr != nil {
+		return OutcomeFailed, allErr
+	}
+
+	if !changed {
+		if updatesEnabled {
+			if err := o.migrateGolden(filename, logf); err != nil {
+				return OutcomeFailed, err
+			}
+		}
+
+		o.recordUnchanged(filename, previous)
+		return OutcomePassed, nil
+	}
+
+	data, err := format.encode(kept)
+	if err != nil {
+		return OutcomeFailed, err
+	}
+
+	if approved, err := o.approveUpdate(filename, changes, data, logf); err != nil {
+		return OutcomeFailed, err
+	} else if !approved {
+		o.reportSkipped(filename, updateRejectedReason, previous, data)
+
+		return OutcomeFailed, multierr.Append(errUpdateRejected, changes)
+	}
+
+	if err := o.writeGolden(filename, previous, data, logf); err != nil {
+		return OutcomeFailed, err
+	}
+
+	if previous == nil {
+		return OutcomeCreated, nil
+	}
+
+	return OutcomeUpdated, nil
+}
@@ -0,0 +1,336 @@
+package aurum
+
+import (
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hansmi/aurum/internal/testutil"
+	"github.com/hansmi/aurum/internal/txtar"
+)
+
+type fakeTB struct {
+	name     string
+	failed   bool
+	errors   []string
+	logs     []string
+	cleanups []func()
+}
+
+var _ SnapshotTB = (*fakeTB)(nil)
+
+func (*fakeTB) Helper() {}
+
+func (t *fakeTB) Name() string {
+	return t.name
+}
+
+func (t *fakeTB) Failed() bool {
+	return t.failed || len(t.errors) > 0
+}
+
+func (t *fakeTB) Errorf(format string, args ...any) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func (t *fakeTB) Logf(format string, args ...any) {
+	t.logs = append(t.logs, fmt.Sprintf(format, args...))
+}
+
+func (t *fakeTB) Cleanup(fn func()) {
+	t.cleanups = append(t.cleanups, fn)
+}
+
+func (t *fakeTB) runCleanups() {
+	for len(t.cleanups) > 0 {
+		fn := t.cleanups[len(t.cleanups)-1]
+		t.cleanups = t.cleanups[:len(t.cleanups)-1]
+		fn()
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	type step struct {
+		updatesEnabled bool
+		values         map[string]any
+		wantErrors     []string
+		wantContent    string
+	}
+
+	for _, tc := range []struct {
+		name  string
+		codec Codec
+		steps []step
+	}{
+		{
+			name: "json",
+			steps: []step{
+				{
+					updatesEnabled: true,
+					values: map[string]any{
+						"first":  []int{1, 2},
+						"second": "text",
+					},
+					wantContent: `{
+  "first": [
+    1,
+    2
+  ],
+  "second": "text"
+}
+`,
+				},
+				{
+					values: map[string]any{
+						"first":  []int{1, 2},
+						"second": "text",
+					},
+				},
+				{
+					values: map[string]any{
+						"second": "changed",
+						"third":  true,
+					},
+					wantErrors: []string{
+						`snapshot entry "second": values are not equal`,
+						`snapshot entry "third": snapshot entry is missing`,
+						`snapshot entry "first": snapshot entry was not asserted`,
+					},
+				},
+				{
+					updatesEnabled: true,
+					values: map[string]any{
+						"second": "changed",
+						"third":  true,
+					},
+					wantContent: `{
+  "second": "changed",
+  "third": true
+}
+`,
+				},
+			},
+		},
+		{
+			name:  "txtar",
+			codec: &TextCodec{},
+			steps: []step{
+				{
+					updatesEnabled: true,
+					values: map[string]any{
+						"first":  "line\n",
+						"second": "no newline",
+					},
+					wantContent: "no-final-newline: second\n-- first --\nline\n-- second --\nno newline\n",
+				},
+				{
+					values: map[string]any{
+						"first":  "line\n",
+						"second": "no newline",
+					},
+				},
+				{
+					updatesEnabled: true,
+					values: map[string]any{
+						"second": "no newline",
+						"first":  "changed\n",
+					},
+					wantContent: "no-final-newline: second\n-- first --\nchanged\n-- second --\nno newline\n",
+				},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			for idx, s := range tc.steps {
+				o := &Golden{
+					g: &globalOptions{
+						updatesEnabled: s.updatesEnabled,
+					},
+					Dir:   dir,
+					Codec: tc.codec,
+				}
+
+				tb := &fakeTB{name: "TestSnapshot/" + tc.name}
+				snap := o.Snapshot(tb)
+
+				for _, name := range slices.Sorted(maps.Keys(s.values)) {
+					snap.Add(name, s.values[name])
+				}
+
+				tb.runCleanups()
+
+				var gotErrors []string
+
+				for _, msg := range tb.errors {
+					msg, _, _ = strings.Cut(msg, " (")
+					gotErrors = append(gotErrors, msg)
+				}
+
+				if diff := cmp.Diff(s.wantErrors, gotErrors); diff != "" {
+					t.Errorf("Step %d: errors diff (-want +got):\n%s", idx, diff)
+				}
+
+				if s.wantContent != "" {
+					got, err := os.ReadFile(filepath.Join(dir, "TestSnapshot%2F"+tc.name))
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					if diff := cmp.Diff(s.wantContent, string(got)); diff != "" {
+						t.Errorf("Step %d: content diff (-want +got):\n%s", idx, diff)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestSnapshotAdd(t *testing.T) {
+	o := &Golden{
+		g:   &globalOptions{},
+		Dir: t.TempDir(),
+	}
+
+	testutil.MustWriteFile(t, filepath.Join(o.Dir, "test"), `{"value": 1}`)
+
+	tb := &fakeTB{name: "test"}
+	snap := o.Snapshot(tb)
+
+	for _, name := range []string{"", " space", "line\nbreak"} {
+		snap.Add(name, 0)
+	}
+
+	snap.Add("value", 1)
+	snap.Add("value", 2)
+
+	tb.runCleanups()
+
+	if diff := cmp.Diff([]string{
+		`Invalid snapshot entry name ""`,
+		`Invalid snapshot entry name " space"`,
+		`Invalid snapshot entry name "line\nbreak"`,
+		`Duplicate snapshot entry "value"`,
+	}, tb.errors); diff != "" {
+		t.Errorf("Errors diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestSnapshotPartial(t *testing.T) {
+	const content = "{\n  \"first\": 1,\n  \"second\": 2\n}\n"
+
+	newFilterFlags := func(run string) *flag.FlagSet {
+		fs := flag.NewFlagSet("", flag.PanicOnError)
+		fs.String("test.run", run, "")
+
+		return fs
+	}
+
+	for _, tc := range []struct {
+		name        string
+		g           *globalOptions
+		failed      bool
+		wantErrors  []string
+		wantContent string
+	}{
+		{
+			name:        "subtest filter",
+			g:           &globalOptions{flagSet: newFilterFlags("TestTable/first")},
+			wantErrors:  []string{`snapshot entry "first": values are not equal`},
+			wantContent: content,
+		},
+		{
+			name: "subtest filter update",
+			g: &globalOptions{
+				updatesEnabled: true,
+				flagSet:        newFilterFlags("TestTable/first"),
+			},
+			wantContent: "{\n  \"first\": 10,\n  \"second\": 2\n}\n",
+		},
+		{
+			name: "top-level filter update",
+			g: &globalOptions{
+				updatesEnabled: true,
+				flagSet:        newFilterFlags("TestTable"),
+			},
+			wantContent: "{\n  \"first\": 10\n}\n",
+		},
+		{
+			name:        "failed",
+			g:           &globalOptions{},
+			failed:      true,
+			wantErrors:  []string{`snapshot entry "first": values are not equal`},
+			wantContent: content,
+		},
+		{
+			name:        "failed update",
+			g:           &globalOptions{updatesEnabled: true},
+			failed:      true,
+			wantContent: content,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			o := &Golden{
+				g:   tc.g,
+				Dir: t.TempDir(),
+			}
+
+			testutil.MustWriteFile(t, filepath.Join(o.Dir, "TestTable"), content)
+
+			tb := &fakeTB{name: "TestTable", failed: tc.failed}
+			snap := o.Snapshot(tb)
+			snap.Add("first", 10)
+
+			tb.runCleanups()
+
+			var gotErrors []string
+
+			for _, msg := range tb.errors {
+				msg, _, _ = strings.Cut(msg, " (")
+				gotErrors = append(gotErrors, msg)
+			}
+
+			if diff := cmp.Diff(tc.wantErrors, gotErrors); diff != "" {
+				t.Errorf("Errors diff (-want +got):\n%s", diff)
+			}
+
+			got, err := os.ReadFile(filepath.Join(o.Dir, "TestTable"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(tc.wantContent, string(got)); diff != "" {
+				t.Errorf("Content diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSnapshotTxtarMarker(t *testing.T) {
+	o := &Golden{
+		g: &globalOptions{
+			updatesEnabled: true,
+		},
+		Dir:   t.TempDir(),
+		Codec: &TextCodec{},
+	}
+
+	tb := &fakeTB{name: "test"}
+	snap := o.Snapshot(tb)
+	snap.Add("a", "line\n-- b --\nmore\n")
+
+	tb.runCleanups()
+
+	if len(tb.errors) != 1 || !strings.Contains(tb.errors[0], txtar.ErrMarker.Error()) {
+		t.Errorf("Snapshot errors %q don't report marker line", tb.errors)
+	}
+
+	testutil.MustNotExist(t, filepath.Join(o.Dir, "test"))
+}
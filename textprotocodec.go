@@ -1,14 +1,24 @@
 package aurum
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/hansmi/aurum/internal/codecutil"
 	"github.com/protocolbuffers/txtpbfmt/parser"
 	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
 )
 
+var defaultTextProtoFormatConfig = parser.Config{
+	ExpandAllChildren:        true,
+	SkipAllColons:            true,
+	WrapStringsAfterNewlines: true,
+}
+
 // TextProtoCodec stores values using the textproto format. Only protocol
 // buffer messages are supported.
 //
@@ -17,11 +27,78 @@ import (
 type TextProtoCodec struct {
 	ProtoMarshalOptions   prototext.MarshalOptions
 	ProtoUnmarshalOptions prototext.UnmarshalOptions
+
+	// Formatting options for txtpbfmt.
+	//
+	// Defaults to expanding all children, skipping colons and wrapping strings
+	// after newlines.
+	FormatConfig *parser.Config
+
+	// Retain "# txtpbfmt:" directives found in the leading comment block of
+	// an existing golden file. The directives are applied when formatting
+	// updated content.
+	KeepFormatDirectives bool
+
+	// Emit "# proto-file:" and "# proto-message:" header comments. Editors
+	// use them for schema-aware highlighting and validation.
+	SchemaComments bool
 }
 
 var _ Codec = (*TextProtoCodec)(nil)
+var _ templateMarshaler = (*TextProtoCodec)(nil)
 
-func (c *TextProtoCodec) Marshal(v any) ([]byte, error) {
+// extractFormatDirectives returns all "# txtpbfmt:" lines from the leading
+// comment block of a textproto file.
+func extractFormatDirectives(data []byte) []string {
+	var result []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if line == "" {
+			continue
+		}
+
+		if !strings.HasPrefix(line, "#") {
+			break
+		}
+
+		if key, _, ok := strings.Cut(line[1:], ":"); ok && strings.TrimSpace(key) == "txtpbfmt" {
+			result = append(result, line)
+		}
+	}
+
+	return result
+}
+
+func (c *TextProtoCodec) header(m proto.Message, template []byte) []byte {
+	var buf bytes.Buffer
+
+	if c.SchemaComments {
+		desc := m.ProtoReflect().Descriptor()
+
+		fmt.Fprintf(&buf, "# proto-file: %s\n", desc.ParentFile().Path())
+		fmt.Fprintf(&buf, "# proto-message: %s\n", desc.FullName())
+	}
+
+	if c.KeepFormatDirectives {
+		for _, line := range extractFormatDirectives(template) {
+			buf.WriteString(line)
+			buf.WriteByte('\n')
+		}
+	}
+
+	if buf.Len() > 0 {
+		// Keep the comments detached from the first field.
+		buf.WriteByte('\n')
+	}
+
+	return buf.Bytes()
+}
+
+func (c *TextProtoCodec) marshalWithTemplate(v any, template []byte) ([]byte, error) {
 	_, m, err := codecutil.PrepareMarshalValue(v)
 	if err != nil {
 		return nil, err
@@ -36,11 +113,17 @@ func (c *TextProtoCodec) Marshal(v any) ([]byte, error) {
 		return nil, err
 	}
 
-	return parser.FormatWithConfig(data, parser.Config{
-		ExpandAllChildren:        true,
-		SkipAllColons:            true,
-		WrapStringsAfterNewlines: true,
-	})
+	config := defaultTextProtoFormatConfig
+
+	if c.FormatConfig != nil {
+		config = *c.FormatConfig
+	}
+
+	return parser.FormatWithConfig(append(c.header(m, template), data...), config)
+}
+
+func (c *TextProtoCodec) Marshal(v any) ([]byte, error) {
+	return c.marshalWithTemplate(v, nil)
 }
 
 func (c *TextProtoCodec) Unmarshal(data []byte, v any) error {
@@ -5,7 +5,9 @@ import (
 	"os"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/hansmi/aurum/internal/codectest"
+	"github.com/protocolbuffers/txtpbfmt/parser"
 	"google.golang.org/protobuf/types/known/structpb"
 	"google.golang.org/protobuf/types/known/wrapperspb"
 )
@@ -62,3 +64,114 @@ func TestTextProtoCodecMarshalEndsWithNewline(t *testing.T) {
 		}
 	}
 }
+
+func TestTextProtoCodecFormat(t *testing.T) {
+	value := func() *structpb.Struct {
+		s, err := structpb.NewStruct(map[string]any{
+			"b": "text",
+			"a": 1,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}()
+
+	for _, tc := range []struct {
+		name     string
+		codec    TextProtoCodec
+		template string
+		want     []string
+		wantNot  []string
+	}{
+		{
+			name:    "defaults",
+			want:    []string{"fields {\n"},
+			wantNot: []string{"fields: {", "# proto-"},
+		},
+		{
+			name: "keep colons",
+			codec: TextProtoCodec{
+				FormatConfig: &parser.Config{
+					ExpandAllChildren: true,
+				},
+			},
+			want: []string{"fields: {\n"},
+		},
+		{
+			name: "schema comments",
+			codec: TextProtoCodec{
+				SchemaComments: true,
+			},
+			want: []string{
+				"# proto-file: google/protobuf/struct.proto\n# proto-message: google.protobuf.Struct\n\n",
+			},
+		},
+		{
+			name:     "directives ignored",
+			template: "# txtpbfmt: skip_all_colons\n",
+			wantNot:  []string{"txtpbfmt"},
+		},
+		{
+			name: "keep directives",
+			codec: TextProtoCodec{
+				FormatConfig:         &parser.Config{},
+				KeepFormatDirectives: true,
+			},
+			template: "# txtpbfmt: skip_all_colons\n# Other comment\nfields {}\n",
+			want:     []string{"# txtpbfmt: skip_all_colons\n", "fields {"},
+			wantNot:  []string{"Other comment", "fields: {"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.codec.marshalWithTemplate(value, []byte(tc.template))
+			if err != nil {
+				t.Fatalf("marshalWithTemplate() failed: %v", err)
+			}
+
+			for _, s := range tc.want {
+				if !bytes.Contains(got, []byte(s)) {
+					t.Errorf("Marshalled value does not contain %q:\n%s", s, got)
+				}
+			}
+
+			for _, s := range tc.wantNot {
+				if bytes.Contains(got, []byte(s)) {
+					t.Errorf("Marshalled value contains %q:\n%s", s, got)
+				}
+			}
+
+			codectest.Assert(t, &tc.codec, codectest.Case{Value: value})
+		})
+	}
+}
+
+func TestExtractFormatDirectives(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{name: "empty"},
+		{
+			name:  "no directives",
+			input: "# comment\nfield: 1\n",
+		},
+		{
+			name:  "leading block",
+			input: "# proto-file: x.proto\n\n#txtpbfmt: disable\n# txtpbfmt : sort_fields_by_field_name\nfield: 1\n# txtpbfmt: skip_all_colons\n",
+			want: []string{
+				"#txtpbfmt: disable",
+				"# txtpbfmt : sort_fields_by_field_name",
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := extractFormatDirectives([]byte(tc.input))
+
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Directives diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package aurum
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing/fstest"
+
+	"github.com/hansmi/aurum/internal/txtar"
+)
+
+// TxtarFS is a filesystem backed by a single txtar archive on disk (see
+// [golang.org/x/tools/txtar]). It allows keeping all golden files of a test
+// in one reviewable file.
+//
+// Writing a file replaces the member in-place or appends a new member to the
+// end of the archive, leaving the order of existing members unchanged. The
+// archive file is created on the first write if it doesn't exist. Data
+// containing lines of the form "-- NAME --" is rejected as it would split
+// the member. For the same reason snapshots (see [Golden.Snapshot]) can only
+// be stored with the JSON codec.
+type TxtarFS struct {
+	path string
+	mu   sync.Mutex
+}
+
+var _ fs.FS = (*TxtarFS)(nil)
+var _ WriteFileFS = (*TxtarFS)(nil)
+
+// NewTxtarFS returns a filesystem backed by the archive at the given path.
+func NewTxtarFS(path string) *TxtarFS {
+	return &TxtarFS{
+		path: path,
+	}
+}
+
+func (f *TxtarFS) readArchive() (*txtar.Archive, error) {
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &txtar.Archive{}, nil
+		}
+
+		return nil, err
+	}
+
+	return txtar.Parse(data), nil
+}
+
+func (f *TxtarFS) Open(name string) (fs.File, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	a, err := f.readArchive()
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+
+	m := fstest.MapFS{}
+
+	for _, file := range a.Files {
+		m[file.Name] = &fstest.MapFile{
+			Data: file.Data,
+			Mode: 0o644,
+		}
+	}
+
+	return m.Open(name)
+}
+
+// lockPath returns the path of the archive as all members share it.
+func (f *TxtarFS) lockPath(string) (string, error) {
+	return filepath.Abs(f.path)
+}
+
+func (f *TxtarFS) WriteFile(name string, data []byte, perm os.FileMode) error {
+	if !fs.ValidPath(name) || name == "." {
+		return &fs.PathError{Op: "write", Path: name, Err: fs.ErrInvalid}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	a, err := f.readArchive()
+	if err != nil {
+		return err
+	}
+
+	found := false
+
+	for idx := range a.Files {
+		if a.Files[idx].Name == name {
+			a.Files[idx].Data = data
+			found = true
+		}
+	}
+
+	if !found {
+		a.Files = append(a.Files, txtar.File{
+			Name: name,
+			Data: data,
+		})
+	}
+
+	content, err := txtar.Format(a)
+	if err != nil {
+		return &fs.PathError{Op: "write", Path: name, Err: err}
+	}
+
+	return os.WriteFile(f.path, content, perm)
+}
@@ -0,0 +1,138 @@
+package aurum
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/hansmi/aurum/internal/testutil"
+	"github.com/hansmi/aurum/internal/txtar"
+)
+
+func TestTxtarFS(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.txtar")
+
+	f := NewTxtarFS(path)
+
+	if _, err := fs.ReadFile(f, "missing"); !os.IsNotExist(err) {
+		t.Errorf("ReadFile() returned %v, want %v", err, os.ErrNotExist)
+	}
+
+	for _, w := range []struct {
+		name string
+		data string
+	}{
+		{"second", "2\n"},
+		{"first", "1"},
+		{"second", "changed\n"},
+		{"dir/third", "3\n"},
+	} {
+		if err := f.WriteFile(w.name, []byte(w.data), 0o644); err != nil {
+			t.Errorf("WriteFile(%q) failed: %v", w.name, err)
+		}
+	}
+
+	if err := f.WriteFile("../invalid", nil, 0o644); err == nil {
+		t.Errorf("WriteFile() with invalid path succeeded")
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "no-final-newline: first\n-- second --\nchanged\n-- first --\n1\n-- dir/third --\n3\n"
+
+	if diff := cmp.Diff(want, string(content)); diff != "" {
+		t.Errorf("Archive diff (-want +got):\n%s", diff)
+	}
+
+	if err := fstest.TestFS(f, "first", "second", "dir/third"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGoldenAssertTxtarFS(t *testing.T) {
+	path := testutil.MustWriteFile(t, filepath.Join(t.TempDir(), "test.txtar"),
+		"comment\n-- existing --\n[1]\n")
+
+	for _, updatesEnabled := range []bool{false, true} {
+		o := &Golden{
+			g: &globalOptions{
+				updatesEnabled: updatesEnabled,
+			},
+			FS: NewTxtarFS(path),
+		}
+
+		err := o.assert("existing", []int{1}, t.Logf)
+		if err != nil {
+			t.Errorf("assert() failed: %v", err)
+		}
+
+		err = o.assert("new", "value", t.Logf)
+
+		var wantErr error
+
+		if !updatesEnabled {
+			wantErr = os.ErrNotExist
+		}
+
+		if diff := cmp.Diff(wantErr, err, cmpopts.EquateErrors()); diff != "" {
+			t.Errorf("Error diff (-want +got):\n%s", diff)
+		}
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff("comment\n-- existing --\n[1]\n-- new --\n\"value\"\n", string(content)); diff != "" {
+		t.Errorf("Archive diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestTxtarFSMarker(t *testing.T) {
+	path := testutil.MustWriteFile(t, filepath.Join(t.TempDir(), "test.txtar"), "-- a --\n1\n")
+
+	f := NewTxtarFS(path)
+
+	if err := f.WriteFile("a", []byte("line\n-- b --\nmore\n"), 0o644); !errors.Is(err, txtar.ErrMarker) {
+		t.Errorf("WriteFile() returned %v, want %v", err, txtar.ErrMarker)
+	}
+
+	if content, err := os.ReadFile(path); err != nil {
+		t.Fatal(err)
+	} else if diff := cmp.Diff("-- a --\n1\n", string(content)); diff != "" {
+		t.Errorf("Archive diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestSnapshotTxtarFS(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.txtar")
+
+	o := &Golden{
+		g: &globalOptions{
+			updatesEnabled: true,
+		},
+		FS:    NewTxtarFS(path),
+		Codec: &TextCodec{},
+	}
+
+	tb := &fakeTB{name: "test"}
+	snap := o.Snapshot(tb)
+	snap.Add("a", "value\n")
+
+	tb.runCleanups()
+
+	if diff := cmp.Diff([]string{errSnapshotNestedTxtar.Error()}, tb.errors); diff != "" {
+		t.Errorf("Errors diff (-want +got):\n%s", diff)
+	}
+
+	testutil.MustNotExist(t, path)
+}
@@ -0,0 +1,188 @@
+package aurum
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io"
+	"unicode"
+)
+
+func innerCodec(c Codec) Codec {
+	if c == nil {
+		return &TextCodec{}
+	}
+
+	return c
+}
+
+// Base64Codec encodes the data produced by an inner codec using base64.
+type Base64Codec struct {
+	// Codec for marshalling and unmarshalling values.
+	//
+	// Defaults to [TextCodec], i.e. byte slices and strings are encoded
+	// directly.
+	Inner Codec
+
+	// Defaults to [base64.StdEncoding].
+	Encoding *base64.Encoding
+
+	// Maximum length of output lines. Zero selects 76 characters, negative
+	// values disable wrapping.
+	LineLength int
+}
+
+var _ Codec = (*Base64Codec)(nil)
+var _ templateMarshaler = (*Base64Codec)(nil)
+
+func (c *Base64Codec) encoding() *base64.Encoding {
+	if c.Encoding == nil {
+		return base64.StdEncoding
+	}
+
+	return c.Encoding
+}
+
+func (c *Base64Codec) decode(data []byte) ([]byte, error) {
+	data = bytes.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+
+		return r
+	}, data)
+
+	enc := c.encoding()
+	buf := make([]byte, enc.DecodedLen(len(data)))
+
+	n, err := enc.Decode(buf, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf[:n], nil
+}
+
+func (c *Base64Codec) marshalWithTemplate(v any, template []byte) ([]byte, error) {
+	if template != nil {
+		// Formatting hints are only available from valid templates.
+		template, _ = c.decode(template)
+	}
+
+	data, err := marshalWithTemplate(innerCodec(c.Inner), v, template)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded := c.encoding().EncodeToString(data)
+
+	lineLength := c.LineLength
+
+	if lineLength == 0 {
+		lineLength = 76
+	} else if lineLength < 0 {
+		lineLength = len(encoded)
+	}
+
+	var buf bytes.Buffer
+
+	for len(encoded) > 0 {
+		line := encoded[:min(lineLength, len(encoded))]
+		encoded = encoded[len(line):]
+
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (c *Base64Codec) Marshal(v any) ([]byte, error) {
+	return c.marshalWithTemplate(v, nil)
+}
+
+func (c *Base64Codec) Unmarshal(data []byte, v any) error {
+	decoded, err := c.decode(data)
+	if err != nil {
+		return err
+	}
+
+	return innerCodec(c.Inner).Unmarshal(decoded, v)
+}
+
+// GzipCodec compresses the data produced by an inner codec using gzip. The
+// gzip header contains neither a modification time nor a filename to produce
+// reproducible output.
+type GzipCodec struct {
+	// Codec for marshalling and unmarshalling values.
+	//
+	// Defaults to [TextCodec], i.e. byte slices and strings are compressed
+	// directly.
+	Inner Codec
+
+	// Compression level (see [gzip.NewWriterLevel]). Zero selects
+	// [gzip.DefaultCompression].
+	Level int
+}
+
+var _ Codec = (*GzipCodec)(nil)
+var _ templateMarshaler = (*GzipCodec)(nil)
+
+func gunzip(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
+
+func (c *GzipCodec) marshalWithTemplate(v any, template []byte) ([]byte, error) {
+	if template != nil {
+		// Formatting hints are only available from valid templates.
+		template, _ = gunzip(template)
+	}
+
+	data, err := marshalWithTemplate(innerCodec(c.Inner), v, template)
+	if err != nil {
+		return nil, err
+	}
+
+	level := c.Level
+
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+
+	var buf bytes.Buffer
+
+	w, err := gzip.NewWriterLevel(&buf, level)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (c *GzipCodec) Marshal(v any) ([]byte, error) {
+	return c.marshalWithTemplate(v, nil)
+}
+
+func (c *GzipCodec) Unmarshal(data []byte, v any) error {
+	decompressed, err := gunzip(data)
+	if err != nil {
+		return err
+	}
+
+	return innerCodec(c.Inner).Unmarshal(decompressed, v)
+}
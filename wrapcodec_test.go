@@ -0,0 +1,135 @@
+package aurum
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hansmi/aurum/internal/codectest"
+	"github.com/hansmi/aurum/internal/ref"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestWrapperCodecs(t *testing.T) {
+	textTests := []codectest.Case{
+		{
+			Name:  "byte slice",
+			Value: []byte("byte value\x00\xff"),
+		},
+		{
+			Name:  "long string",
+			Value: strings.Repeat("0123456789", 100),
+		},
+		{
+			Name:           "empty struct",
+			Value:          struct{}{},
+			WantMarshalErr: os.ErrInvalid,
+		},
+	}
+
+	jsonTests := []codectest.Case{
+		{
+			Name:  "int slice",
+			Value: []int{0, 1, 2, 3},
+		},
+		{
+			Name:  "empty struct",
+			Value: struct{}{},
+		},
+		{
+			Name:  "proto int64value",
+			Value: &wrapperspb.Int64Value{Value: 4321},
+		},
+	}
+
+	for _, tc := range []struct {
+		name  string
+		codec Codec
+		tests []codectest.Case
+	}{
+		{
+			name:  "base64",
+			codec: &Base64Codec{},
+			tests: textTests,
+		},
+		{
+			name: "base64 url without wrapping",
+			codec: &Base64Codec{
+				Encoding:   base64.RawURLEncoding,
+				LineLength: -1,
+			},
+			tests: textTests,
+		},
+		{
+			name:  "base64 json",
+			codec: &Base64Codec{Inner: &JSONCodec{}},
+			tests: jsonTests,
+		},
+		{
+			name:  "gzip",
+			codec: &GzipCodec{},
+			tests: textTests,
+		},
+		{
+			name: "gzip json",
+			codec: &GzipCodec{
+				Inner: &JSONCodec{},
+				Level: gzip.BestCompression,
+			},
+			tests: jsonTests,
+		},
+		{
+			name: "base64 gzip json",
+			codec: &Base64Codec{
+				Inner: &GzipCodec{Inner: &JSONCodec{}},
+			},
+			tests: jsonTests,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			codectest.AssertAll(t, tc.codec, tc.tests)
+		})
+	}
+}
+
+func TestBase64CodecMarshal(t *testing.T) {
+	got, err := (&Base64Codec{LineLength: 8}).Marshal(ref.Ref([]byte("Hello, World!")))
+	if err != nil {
+		t.Fatalf("Marshal() failed: %v", err)
+	}
+
+	if diff := cmp.Diff("SGVsbG8s\nIFdvcmxk\nIQ==\n", string(got)); diff != "" {
+		t.Errorf("Marshal() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestGzipCodecReproducible(t *testing.T) {
+	c := &GzipCodec{Inner: &JSONCodec{}}
+
+	first, err := c.Marshal([]string{"hello", "world"})
+	if err != nil {
+		t.Fatalf("Marshal() failed: %v", err)
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(first))
+	if err != nil {
+		t.Fatalf("NewReader() failed: %v", err)
+	}
+
+	if diff := cmp.Diff(gzip.Header{OS: r.Header.OS}, r.Header); diff != "" {
+		t.Errorf("Header diff (-want +got):\n%s", diff)
+	}
+
+	second, err := c.Marshal([]string{"hello", "world"})
+	if err != nil {
+		t.Fatalf("Marshal() failed: %v", err)
+	}
+
+	if !bytes.Equal(first, second) {
+		t.Errorf("Marshal() output differs: %q != %q", first, second)
+	}
+}
@@ -0,0 +1,188 @@
+package aurum
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/hansmi/aurum/internal/codecutil"
+)
+
+// XMLCodec stores values using the XML format via [encoding/xml]. Protocol
+// buffer messages are not supported.
+//
+// Attributes are written in sorted order, namespace declarations first, to
+// produce stable output regardless of struct field order. Indentation is
+// only applied between elements; values with mixed content may not survive
+// the round-trip.
+type XMLCodec struct {
+	// Indentation for nested elements. Defaults to two spaces.
+	Indent string
+
+	// Disable indentation and write the whole document on a single line.
+	Compact bool
+
+	// Name of the root element. Defaults to the name determined by
+	// [xml.Marshal], i.e. the XMLName field or the type name. Values with a
+	// tagged XMLName field can only be unmarshalled if the names match.
+	RootName string
+
+	// Emit the standard XML declaration ([xml.Header]).
+	Declaration bool
+}
+
+var _ Codec = (*XMLCodec)(nil)
+
+// xmlFlatName returns a name with the namespace prefix, if any, merged into
+// the local part. RawToken reports prefixes, not namespace URLs, and the
+// encoder would otherwise add bogus namespace declarations.
+func xmlFlatName(n xml.Name) xml.Name {
+	if n.Space != "" {
+		return xml.Name{Local: n.Space + ":" + n.Local}
+	}
+
+	return n
+}
+
+func xmlSortAttrs(attrs []xml.Attr) {
+	isNamespace := func(n xml.Name) bool {
+		return n.Space == "xmlns" || (n.Space == "" && n.Local == "xmlns")
+	}
+
+	sort.SliceStable(attrs, func(i, j int) bool {
+		a, b := attrs[i].Name, attrs[j].Name
+
+		if nsA, nsB := isNamespace(a), isNamespace(b); nsA != nsB {
+			return nsA
+		}
+
+		if a.Space != b.Space {
+			return a.Space < b.Space
+		}
+
+		return a.Local < b.Local
+	})
+}
+
+// reformat re-encodes an XML document with indentation and stable attribute
+// order.
+func (c *XMLCodec) reformat(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	enc := xml.NewEncoder(&buf)
+
+	if !c.Compact {
+		indent := c.Indent
+
+		if indent == "" {
+			indent = "  "
+		}
+
+		enc.Indent("", indent)
+	}
+
+	for {
+		tok, err := dec.RawToken()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			t = t.Copy()
+			t.Name = xmlFlatName(t.Name)
+			xmlSortAttrs(t.Attr)
+
+			for i := range t.Attr {
+				t.Attr[i].Name = xmlFlatName(t.Attr[i].Name)
+			}
+
+			tok = t
+
+		case xml.EndElement:
+			tok = xml.EndElement{Name: xmlFlatName(t.Name)}
+
+		default:
+			tok = xml.CopyToken(tok)
+		}
+
+		if err := enc.EncodeToken(tok); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (c *XMLCodec) Marshal(v any) ([]byte, error) {
+	rv, m, err := codecutil.PrepareMarshalValue(v)
+	if err != nil {
+		return nil, err
+	}
+
+	if m != nil {
+		return nil, fmt.Errorf("%w: marshalling protobuf message %T as XML is not supported", os.ErrInvalid, v)
+	}
+
+	var buf bytes.Buffer
+
+	enc := xml.NewEncoder(&buf)
+
+	if c.RootName == "" {
+		err = enc.Encode(rv.Interface())
+	} else {
+		err = enc.EncodeElement(rv.Interface(), xml.StartElement{
+			Name: xml.Name{Local: c.RootName},
+		})
+	}
+
+	if err == nil {
+		err = enc.Close()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := c.reformat(buf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	buf.Reset()
+
+	if c.Declaration {
+		buf.WriteString(xml.Header)
+	}
+
+	if len(data) > 0 {
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (c *XMLCodec) Unmarshal(data []byte, v any) error {
+	rv, m, err := codecutil.PrepareUnmarshalDest(v)
+	if err != nil {
+		return err
+	}
+
+	if m != nil {
+		return fmt.Errorf("%w: unmarshalling XML into protobuf message %T is not supported", os.ErrInvalid, v)
+	}
+
+	return xml.Unmarshal(data, rv.Interface())
+}
@@ -0,0 +1,144 @@
+package aurum
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hansmi/aurum/internal/codectest"
+	"github.com/hansmi/aurum/internal/ref"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type xmlTestItem struct {
+	Zeta  string `xml:"zeta,attr"`
+	Alpha int    `xml:"alpha,attr,omitempty"`
+	Text  string `xml:",chardata"`
+}
+
+type xmlTestEmpty struct{}
+
+type xmlTestDocument struct {
+	XMLName xml.Name      `xml:"document"`
+	Title   string        `xml:"title"`
+	Items   []xmlTestItem `xml:"items>item"`
+}
+
+func TestXMLCodec(t *testing.T) {
+	tests := []codectest.Case{
+		{
+			Name:  "string",
+			Value: "hello world",
+		},
+		{
+			Name:  "string pointer",
+			Value: ref.Ref("hello world"),
+		},
+		{
+			Name:  "int",
+			Value: 1234,
+		},
+		{
+			Name:  "empty struct",
+			Value: xmlTestEmpty{},
+		},
+		{
+			Name: "document",
+			Value: xmlTestDocument{
+				XMLName: xml.Name{Local: "document"},
+				Title:   "Example",
+				Items: []xmlTestItem{
+					{Zeta: "z", Alpha: 1, Text: "first"},
+					{Zeta: "y", Text: "second"},
+				},
+			},
+		},
+		{
+			Name:           "proto int64value",
+			Value:          &wrapperspb.Int64Value{Value: 4321},
+			WantMarshalErr: os.ErrInvalid,
+		},
+	}
+
+	codectest.AssertAll(t, &XMLCodec{}, tests)
+
+	t.Run("options", func(t *testing.T) {
+		codectest.AssertAll(t, &XMLCodec{
+			Indent:      "\t",
+			RootName:    "document",
+			Declaration: true,
+		}, tests)
+	})
+
+	t.Run("compact", func(t *testing.T) {
+		codectest.AssertAll(t, &XMLCodec{Compact: true}, tests)
+	})
+}
+
+func TestXMLCodecMarshal(t *testing.T) {
+	type envelope struct {
+		XMLName xml.Name `xml:"soap:Envelope"`
+		Soap    string   `xml:"xmlns:soap,attr"`
+		Style   string   `xml:"style,attr"`
+		Body    string   `xml:"soap:Body"`
+	}
+
+	for _, tc := range []struct {
+		name  string
+		codec XMLCodec
+		value any
+		want  string
+	}{
+		{
+			name:  "sorted attributes",
+			value: xmlTestItem{Zeta: "z", Alpha: 1, Text: "text"},
+			want:  `<xmlTestItem alpha="1" zeta="z">text</xmlTestItem>` + "\n",
+		},
+		{
+			name:  "indented",
+			value: xmlTestDocument{Title: "x", Items: []xmlTestItem{{Zeta: "a"}}},
+			want: `<document>
+  <title>x</title>
+  <items>
+    <item zeta="a"></item>
+  </items>
+</document>
+`,
+		},
+		{
+			name:  "root name",
+			codec: XMLCodec{RootName: "value", Compact: true},
+			value: []byte("data"),
+			want:  "<value>data</value>\n",
+		},
+		{
+			name:  "declaration",
+			codec: XMLCodec{Declaration: true},
+			value: true,
+			want:  xml.Header + "<bool>true</bool>\n",
+		},
+		{
+			name:  "namespace prefix",
+			codec: XMLCodec{Compact: true},
+			value: envelope{Style: "rpc", Soap: "urn:soap", Body: "content"},
+			want:  `<soap:Envelope xmlns:soap="urn:soap" style="rpc"><soap:Body>content</soap:Body></soap:Envelope>` + "\n",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.codec.Marshal(tc.value)
+			if err != nil {
+				t.Fatalf("Marshal() failed: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.want, string(got)); diff != "" {
+				t.Errorf("Marshal() diff (-want +got):\n%s", diff)
+			}
+
+			if !bytes.HasSuffix(got, []byte{'\n'}) {
+				t.Errorf("Marshal() return value does not end in newline: %q", got)
+			}
+		})
+	}
+}